@@ -1,7 +1,12 @@
 package assistdog
 
 import (
+	"encoding"
 	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"strings"
 	"sync"
@@ -13,15 +18,68 @@ import (
 )
 
 var defaultParsers = map[interface{}]ParseFunc{
-	"":          defaults.ParseString,
-	0:           defaults.ParseInt,
-	time.Time{}: defaults.ParseTime,
+	"":                defaults.ParseString,
+	false:             defaults.ParseBool,
+	0:                 defaults.ParseInt,
+	int8(0):           defaults.ParseInt8,
+	int16(0):          defaults.ParseInt16,
+	int32(0):          defaults.ParseInt32,
+	int64(0):          defaults.ParseInt64,
+	uint(0):           defaults.ParseUint,
+	uint8(0):          defaults.ParseUint8,
+	uint16(0):         defaults.ParseUint16,
+	uint32(0):         defaults.ParseUint32,
+	uint64(0):         defaults.ParseUint64,
+	float32(0):        defaults.ParseFloat32,
+	float64(0):        defaults.ParseFloat64,
+	time.Time{}:       defaults.ParseTime,
+	time.Duration(0):  defaults.ParseDuration,
+	new(big.Int):      defaults.ParseBigInt,
+	new(big.Float):    defaults.ParseBigFloat,
+	new(big.Rat):      defaults.ParseBigRat,
+	new(net.IPNet):    defaults.ParseIPNet,
+	new(url.URL):      defaults.ParseURL,
+	new(mail.Address): defaults.ParseMailAddress,
 }
 
 var defaultComparers = map[interface{}]CompareFunc{
-	"":          defaults.CompareString,
-	0:           defaults.CompareInt,
-	time.Time{}: defaults.CompareTime,
+	"":                defaults.CompareString,
+	false:             defaults.CompareBool,
+	0:                 defaults.CompareInt,
+	int8(0):           defaults.CompareInt8,
+	int16(0):          defaults.CompareInt16,
+	int32(0):          defaults.CompareInt32,
+	int64(0):          defaults.CompareInt64,
+	uint(0):           defaults.CompareUint,
+	uint8(0):          defaults.CompareUint8,
+	uint16(0):         defaults.CompareUint16,
+	uint32(0):         defaults.CompareUint32,
+	uint64(0):         defaults.CompareUint64,
+	float32(0):        defaults.CompareFloat32,
+	float64(0):        defaults.CompareFloat64,
+	time.Time{}:       defaults.CompareTime,
+	time.Duration(0):  defaults.CompareDuration,
+	new(big.Int):      defaults.CompareBigInt,
+	new(big.Float):    defaults.CompareBigFloat,
+	new(big.Rat):      defaults.CompareBigRat,
+	new(net.IPNet):    defaults.CompareIPNet,
+	new(url.URL):      defaults.CompareURL,
+	new(mail.Address): defaults.CompareMailAddress,
+}
+
+var defaultInterfaceParsers = []struct {
+	iface interface{}
+	parse InterfaceParseFunc
+}{
+	{iface: (*encoding.TextUnmarshaler)(nil), parse: defaults.ParseTextUnmarshaler},
+}
+
+var defaultInterfaceComparers = []struct {
+	iface   interface{}
+	compare CompareFunc
+}{
+	{iface: (*encoding.TextMarshaler)(nil), compare: defaults.CompareTextMarshaler},
+	{iface: (*fmt.Stringer)(nil), compare: defaults.CompareStringer},
 }
 
 // ParseFunc parses a raw string value from a table into a given type.
@@ -34,7 +92,15 @@ type ParseFunc func(raw string) (interface{}, error)
 // describes the differences should be returned.
 type CompareFunc func(raw string, actual interface{}) error
 
+// InterfaceParseFunc parses a raw string value from a table into a type that implements a
+// registered interface. The target type is given so that a new value of it can be created.
+// If only a pointer to the target type implements the interface, the returned value must still
+// be of the target type itself.
+type InterfaceParseFunc func(tp reflect.Type, raw string) (interface{}, error)
+
 // NewDefault creates a new Assist instance with all the default parsers and comparers.
+// Besides the types registered directly, types implementing encoding.TextUnmarshaler are parsed
+// through it, and types implementing encoding.TextMarshaler or fmt.Stringer are compared through them.
 func NewDefault() *Assist {
 	a := new(Assist)
 	for tp, p := range defaultParsers {
@@ -45,6 +111,28 @@ func NewDefault() *Assist {
 		a.RegisterComparer(tp, c)
 	}
 
+	// Slices can't be used as map keys, so they are registered separately.
+	a.RegisterParser([]byte{}, defaults.ParseBytes)
+	a.RegisterComparer([]byte{}, defaults.CompareBytes)
+	a.RegisterParser(net.IP{}, defaults.ParseIP)
+	a.RegisterComparer(net.IP{}, defaults.CompareIP)
+	a.RegisterParser(net.HardwareAddr{}, defaults.ParseHardwareAddr)
+	a.RegisterComparer(net.HardwareAddr{}, defaults.CompareHardwareAddr)
+
+	// Value fields of types whose defaults work with pointers go through them.
+	a.RegisterParser(net.IPNet{}, valueParser(defaults.ParseIPNet))
+	a.RegisterComparer(net.IPNet{}, valueComparer(defaults.CompareIPNet))
+	a.RegisterParser(mail.Address{}, valueParser(defaults.ParseMailAddress))
+	a.RegisterComparer(mail.Address{}, valueComparer(defaults.CompareMailAddress))
+
+	for _, ip := range defaultInterfaceParsers {
+		a.RegisterInterfaceParser(ip.iface, ip.parse)
+	}
+
+	for _, ic := range defaultInterfaceComparers {
+		a.RegisterInterfaceComparer(ic.iface, ic.compare)
+	}
+
 	return a
 }
 
@@ -53,8 +141,29 @@ type Assist struct {
 	lock      sync.RWMutex
 	parsers   map[reflect.Type]ParseFunc
 	comparers map[reflect.Type]CompareFunc
+	bools     *defaults.BoolVocabulary
+	times     *defaults.TimeParser
+	floats    *defaults.FloatComparer
+
+	interfaceParsers   []interfaceParser
+	interfaceComparers []interfaceComparer
+	nullTokens         []string
+	listDelimiter      rune
+	sliceOrder         SliceOrder
+
+	mapEntryDelimiter    rune
+	mapKeyValueDelimiter rune
+	jsonCells            bool
+	matchers             map[string]MatchFunc
+	headerNormalizer     func(string) string
+	fieldParsers         map[fieldKey]ParseFunc
+	fieldComparers       map[fieldKey]CompareFunc
 }
 
+// DefaultNullTokens are the values that represent nil for pointer, slice and map fields,
+// unless replaced with SetNullTokens.
+var DefaultNullTokens = []string{"<nil>"}
+
 // RegisterParser registers a new value parser for a type.
 // If a previous parser already exists for the given type, it will be replaced.
 func (a *Assist) RegisterParser(i interface{}, parser ParseFunc) {
@@ -89,6 +198,188 @@ func (a *Assist) RemoveComparer(i interface{}) {
 	delete(a.comparers, reflect.TypeOf(i))
 }
 
+// RegisterInterfaceParser registers a value parser for all types that implement an interface.
+// The interface must be given as a nil pointer to it, such as (*encoding.TextUnmarshaler)(nil).
+// Interface parsers are only used for types that have no parser registered for them directly,
+// and they are tried in the order they were registered.
+// If a previous parser already exists for the given interface, it will be replaced.
+func (a *Assist) RegisterInterfaceParser(iface interface{}, parser InterfaceParseFunc) {
+	it := interfaceType(iface)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, ip := range a.interfaceParsers {
+		if ip.iface == it {
+			a.interfaceParsers[i].parse = parser
+			return
+		}
+	}
+
+	a.interfaceParsers = append(a.interfaceParsers, interfaceParser{iface: it, parse: parser})
+}
+
+// RegisterInterfaceComparer registers a value comparer for all types that implement an interface.
+// The interface must be given as a nil pointer to it, such as (*fmt.Stringer)(nil).
+// Interface comparers are only used for types that have no comparer registered for them directly,
+// and they are tried in the order they were registered.
+// If a previous comparer already exists for the given interface, it will be replaced.
+func (a *Assist) RegisterInterfaceComparer(iface interface{}, comparer CompareFunc) {
+	it := interfaceType(iface)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, ic := range a.interfaceComparers {
+		if ic.iface == it {
+			a.interfaceComparers[i].compare = comparer
+			return
+		}
+	}
+
+	a.interfaceComparers = append(a.interfaceComparers, interfaceComparer{iface: it, compare: comparer})
+}
+
+// RemoveInterfaceParser removes the value parser for an interface.
+func (a *Assist) RemoveInterfaceParser(iface interface{}) {
+	it := interfaceType(iface)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, ip := range a.interfaceParsers {
+		if ip.iface == it {
+			a.interfaceParsers = append(a.interfaceParsers[:i], a.interfaceParsers[i+1:]...)
+			return
+		}
+	}
+}
+
+// RemoveInterfaceComparer removes the value comparer for an interface.
+func (a *Assist) RemoveInterfaceComparer(iface interface{}) {
+	it := interfaceType(iface)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, ic := range a.interfaceComparers {
+		if ic.iface == it {
+			a.interfaceComparers = append(a.interfaceComparers[:i], a.interfaceComparers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetNullTokens replaces the values that represent nil for pointer, slice and map fields.
+// Tokens are matched case-insensitively, ignoring surrounding whitespace.
+// Calling it without any tokens disables nil values altogether.
+func (a *Assist) SetNullTokens(tokens ...string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.nullTokens = append([]string{}, tokens...)
+}
+
+// SetBoolVocabulary replaces the words accepted as true and false for bool values.
+// It registers a new parser and comparer for bool, replacing any previous ones.
+func (a *Assist) SetBoolVocabulary(vocabulary defaults.BoolVocabulary) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	a.setBoolVocabulary(vocabulary)
+}
+
+// ExtendBoolVocabulary adds words to the ones currently accepted as true and false for bool values.
+// It registers a new parser and comparer for bool, replacing any previous ones.
+func (a *Assist) ExtendBoolVocabulary(truthy, falsy []string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	current := defaults.DefaultBoolVocabulary
+	if a.bools != nil {
+		current = *a.bools
+	}
+
+	a.setBoolVocabulary(current.Extend(truthy, falsy))
+}
+
+// SetDecimalRule defines how decimal values written in a table are compared to *big.Float and *big.Rat values.
+// It registers new comparers for both types, replacing any previous ones.
+func (a *Assist) SetDecimalRule(rule defaults.DecimalRule) {
+	c := defaults.DecimalComparer{Rule: rule}
+	a.RegisterComparer(new(big.Float), c.CompareBigFloat)
+	a.RegisterComparer(new(big.Rat), c.CompareBigRat)
+}
+
+// SetFloatEpsilon sets the tolerance for float32 and float64 values whose cells don't specify one,
+// such as "3.14 ± 0.01" or "~3.14".
+// It registers new comparers for both types, replacing any previous ones.
+func (a *Assist) SetFloatEpsilon(epsilon float64) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	floats := a.currentFloatComparer()
+	floats.Epsilon = epsilon
+	a.setFloatComparer(floats)
+}
+
+// SetFloatPrecisionInference defines whether the tolerance for float32 and float64 values is inferred
+// from the digits written in every cell, so that 3.14 matches values from 3.135 to 3.145.
+// It registers new comparers for both types, replacing any previous ones.
+func (a *Assist) SetFloatPrecisionInference(enabled bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	floats := a.currentFloatComparer()
+	floats.InferPrecision = enabled
+	a.setFloatComparer(floats)
+}
+
+// SetStringMode defines how string values written in a table are compared to actual strings,
+// such as defaults.StringFold|defaults.StringTrim. Fields can choose their own modes with a tag,
+// as in `assist:",string=fold|trim"`, and cells by starting with them in parentheses, as in "(fold) Hello".
+// It registers a new comparer for string, replacing any previous one.
+func (a *Assist) SetStringMode(mode defaults.StringMode) {
+	a.RegisterComparer("", defaults.StringComparer{Mode: mode}.Compare)
+}
+
+// SetClock replaces the function used to resolve relative time expressions such as "now",
+// "yesterday 09:00" or "2 hours ago", so that scenarios can be deterministic.
+// It registers a new parser and comparer for time.Time, replacing any previous ones.
+func (a *Assist) SetClock(now func() time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	times := a.currentTimeParser()
+	times.Now = now
+	a.setTimeParser(times)
+}
+
+// SetTimeLayouts replaces the layouts accepted for time.Time values, which are tried in order.
+// It registers a new parser and comparer for time.Time, replacing any previous ones.
+func (a *Assist) SetTimeLayouts(layouts ...string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	times := a.currentTimeParser()
+	times.Layouts = append([]string{}, layouts...)
+	a.setTimeParser(times)
+}
+
+// SetTimeLocation sets the location of time.Time values that don't specify a time zone.
+// It registers a new parser and comparer for time.Time, replacing any previous ones.
+func (a *Assist) SetTimeLocation(location *time.Location) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	times := a.currentTimeParser()
+	times.Location = location
+	a.setTimeParser(times)
+}
+
+// SetTimeTolerance sets the maximum difference between expected and actual time.Time values
+// for them to match, which is useful for timestamps generated by the system under test.
+// It registers a new parser and comparer for time.Time, replacing any previous ones.
+func (a *Assist) SetTimeTolerance(tolerance time.Duration) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.assertInit()
+	times := a.currentTimeParser()
+	times.Tolerance = tolerance
+	a.setTimeParser(times)
+}
+
 // ParseMap takes a Gherkin table and returns a map that represents it.
 // The table must have exactly two columns, where the first represents
 // the key and the second represents the value.
@@ -181,6 +472,26 @@ func (a *Assist) CreateSlice(tp interface{}, table *godog.Table) (interface{}, e
 }
 
 // CompareToInstance compares an actual value to the expected fields from a Gherkin table.
+//
+// Besides values, expected cells can hold matcher expressions, regardless of the field's type,
+// which are evaluated before the comparer of the type:
+//
+//	<any>                 matches any value
+//	<empty>               matches zero values and empty strings, slices and maps
+//	<not empty>           matches anything <empty> doesn't
+//	~/^ORD-\d+$/          matches values whose text matches a regular expression
+//	> 10, >= 10, < 10, <= 10
+//	                      match values ordered against one parsed with the type's parser
+//	between 1 and 5       matches values in an inclusive range
+//	!= Pending            matches values the type's comparer doesn't match
+//	contains foo          matches strings with a substring, and slices or maps with an element or key
+//	{uuid}                matches values accepted by a matcher registered with RegisterMatcher,
+//	                      and fails for names that aren't registered
+//
+// Expressions can be combined with "or", such as `{uuid} or <nil>`, as long as one of the alternatives
+// is a matcher expression; the other alternatives are compared as usual.
+// A cell that starts with a backslash followed by a matcher expression is compared literally,
+// without the backslash.
 func (a *Assist) CompareToInstance(actual interface{}, table *godog.Table) error {
 	tableMap, err := a.ParseMap(table)
 	if err != nil {
@@ -196,6 +507,7 @@ func (a *Assist) CompareToInstance(actual interface{}, table *godog.Table) error
 }
 
 // CompareToSlice compares an actual slice of values to the expected rows from a Gherkin table.
+// Cells can hold the same matcher expressions as in CompareToInstance.
 func (a *Assist) CompareToSlice(actual interface{}, table *godog.Table) error {
 	maps, err := a.ParseSlice(table)
 	if err != nil {
@@ -226,10 +538,16 @@ func (a *Assist) createInstance(tp interface{}, table map[string]string) (reflec
 	errs := []string{}
 	result := reflect.New(reflect.TypeOf(tp).Elem())
 	sv := result.Elem()
-	for fieldName, rawValue := range table {
-		fv := sv.FieldByName(fieldName)
-		if !fv.IsValid() {
-			errs = append(errs, fmt.Sprintf("%v: field not found", fieldName))
+	for _, fieldName := range sortedHeaders(table) {
+		rawValue := table[fieldName]
+		fv, field, err := a.lookupField(sv, fieldName, true)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err))
+			continue
+		}
+
+		if parseTag(field.StructField).ignore {
+			errs = append(errs, fmt.Sprintf("%v: field is ignored", fieldName))
 			continue
 		}
 
@@ -238,19 +556,31 @@ func (a *Assist) createInstance(tp interface{}, table map[string]string) (reflec
 			continue
 		}
 
-		parseField, ok := a.findParser(fv.Type())
-		if !ok {
-			errs = append(errs, fmt.Sprintf("%v: unrecognized type %v", fieldName, fv.Type()))
+		parseField, err := a.fieldParser(field, fv.Type())
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err))
 			continue
 		}
 
+		if parseField == nil {
+			var ok bool
+			if parseField, ok = a.findParser(fv.Type()); !ok {
+				errs = append(errs, fmt.Sprintf("%v: unrecognized type %v", fieldName, fv.Type()))
+				continue
+			}
+		}
+
 		parsed, err := parseField(rawValue)
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err.Error()))
 			continue
 		}
 
-		fv.Set(reflect.ValueOf(parsed))
+		setValue(fv, parsed)
+	}
+
+	for _, missing := range a.missingRequired(sv.Type(), sortedHeaders(table)) {
+		errs = append(errs, fmt.Sprintf("%v: required field missing", missing))
 	}
 
 	return result, errs
@@ -259,20 +589,31 @@ func (a *Assist) createInstance(tp interface{}, table map[string]string) (reflec
 func (a *Assist) compareToInstance(actual interface{}, table map[string]string) []string {
 	errs := []string{}
 	sv := reflect.ValueOf(actual).Elem()
-	for fieldName, rawExpectedValue := range table {
-		fv := sv.FieldByName(fieldName)
-		if !fv.IsValid() {
-			errs = append(errs, fmt.Sprintf("%v: field not found", fieldName))
+	for _, fieldName := range sortedHeaders(table) {
+		rawExpectedValue := table[fieldName]
+		fv, field, err := a.lookupField(sv, fieldName, false)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err))
 			continue
 		}
 
-		compare, ok := a.findComparer(fv.Type())
-		if !ok {
-			errs = append(errs, fmt.Sprintf("%v: unrecognized type %v", fieldName, fv.Type()))
+		if parseTag(field.StructField).ignore {
+			errs = append(errs, fmt.Sprintf("%v: field is ignored", fieldName))
 			continue
 		}
 
-		if err := compare(rawExpectedValue, fv.Interface()); err != nil {
+		if !fv.CanInterface() {
+			errs = append(errs, fmt.Sprintf("%v: cannot read value", fieldName))
+			continue
+		}
+
+		compare, err := a.fieldComparer(field, fv.Type())
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err))
+			continue
+		}
+
+		if err := a.compareValue(rawExpectedValue, fv, compare); err != nil {
 			errs = append(errs, fmt.Sprintf("%v: %v", fieldName, err))
 		}
 	}
@@ -280,18 +621,38 @@ func (a *Assist) compareToInstance(actual interface{}, table map[string]string)
 	return errs
 }
 
-func (a *Assist) findParser(tp reflect.Type) (ParseFunc, bool) {
-	a.lock.RLock()
-	defer a.lock.RUnlock()
-	p, ok := a.parsers[tp]
-	return p, ok
+func (a *Assist) setBoolVocabulary(vocabulary defaults.BoolVocabulary) {
+	a.bools = &vocabulary
+	a.parsers[reflect.TypeOf(false)] = vocabulary.Parse
+	a.comparers[reflect.TypeOf(false)] = vocabulary.Compare
+}
+
+func (a *Assist) currentTimeParser() defaults.TimeParser {
+	if a.times == nil {
+		return defaults.TimeParser{}
+	}
+
+	return *a.times
+}
+
+func (a *Assist) setTimeParser(times defaults.TimeParser) {
+	a.times = &times
+	a.parsers[reflect.TypeOf(time.Time{})] = times.Parse
+	a.comparers[reflect.TypeOf(time.Time{})] = times.Compare
+}
+
+func (a *Assist) currentFloatComparer() defaults.FloatComparer {
+	if a.floats == nil {
+		return defaults.FloatComparer{}
+	}
+
+	return *a.floats
 }
 
-func (a *Assist) findComparer(tp reflect.Type) (CompareFunc, bool) {
-	a.lock.RLock()
-	defer a.lock.RUnlock()
-	c, ok := a.comparers[tp]
-	return c, ok
+func (a *Assist) setFloatComparer(floats defaults.FloatComparer) {
+	a.floats = &floats
+	a.comparers[reflect.TypeOf(float32(0))] = floats.CompareFloat32
+	a.comparers[reflect.TypeOf(float64(0))] = floats.CompareFloat64
 }
 
 func (a *Assist) assertInit() {
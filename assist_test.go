@@ -1,8 +1,13 @@
 package assistdog
 
 import (
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cucumber/godog"
 	"github.com/cucumber/messages-go/v10"
@@ -84,6 +89,367 @@ func TestCreateInstance(t *testing.T) {
 	})
 }
 
+type measurement struct {
+	ID       int64
+	Count    uint32
+	Delta    int8
+	Amount   float64
+	Fraction float32
+}
+
+func TestCreateInstanceWithNumericKinds(t *testing.T) {
+	t.Run("successfully", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"ID", "9007199254740993"},
+			{"Count", "4000000000"},
+			{"Delta", "-12"},
+			{"Amount", "12.75"},
+			{"Fraction", "0.5"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(measurement), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &measurement{
+			ID:       9007199254740993,
+			Count:    4000000000,
+			Delta:    -12,
+			Amount:   12.75,
+			Fraction: 0.5,
+		}, result)
+	})
+
+	t.Run("with value out of range", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Delta", "200"},
+		})
+
+		_, err := NewDefault().CreateInstance(new(measurement), table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `failed to parse table as *assistdog.measurement:
+- Delta: 200 is out of range for int8 (-128 to 127)`, err.Error())
+	})
+
+	t.Run("compares successfully", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"ID", "42"},
+			{"Amount", "12.75"},
+		})
+
+		err := NewDefault().CompareToInstance(&measurement{ID: 42, Amount: 12.75}, table)
+		assert.NoError(t, err)
+	})
+}
+
+type account struct {
+	Name   string
+	Active bool
+}
+
+func TestBoolVocabulary(t *testing.T) {
+	t.Run("creates instance with default vocabulary", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Name", "John"},
+			{"Active", "yes"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(account), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &account{Name: "John", Active: true}, result)
+	})
+
+	t.Run("extends vocabulary", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Active", "enabled"},
+		})
+
+		assist := NewDefault()
+		assist.ExtendBoolVocabulary([]string{"enabled"}, []string{"disabled"})
+
+		err := assist.CompareToInstance(&account{Active: true}, table)
+		assert.NoError(t, err)
+	})
+
+	t.Run("replaces vocabulary", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Active", "yes"},
+		})
+
+		assist := NewDefault()
+		assist.SetBoolVocabulary(defaults.BoolVocabulary{True: []string{"y"}, False: []string{"n"}})
+
+		_, err := assist.CreateInstance(new(account), table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `failed to parse table as *assistdog.account:
+- Active: unrecognized boolean yes, expected one of y, n`, err.Error())
+	})
+}
+
+type retryPolicy struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+func TestDurations(t *testing.T) {
+	t.Run("creates instance", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Timeout", "1h30m"},
+			{"Interval", "90 seconds"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(retryPolicy), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &retryPolicy{Timeout: 90 * time.Minute, Interval: 90 * time.Second}, result)
+	})
+
+	t.Run("compares instance", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Interval", "2 minutes"},
+		})
+
+		err := NewDefault().CompareToInstance(&retryPolicy{Interval: 30 * time.Second}, table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `comparison failed:
+- Interval: expected 2 minutes, but got 0.5 minutes`, err.Error())
+	})
+}
+
+type ledgerEntry struct {
+	Quantity *big.Int
+	Amount   *big.Rat
+	Rate     *big.Float
+}
+
+func TestBigNumbers(t *testing.T) {
+	t.Run("creates instance", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(ledgerEntry), buildTable([][]string{
+			{"Quantity", "100000000000000000000"},
+			{"Amount", "12.50"},
+			{"Rate", "<nil>"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*ledgerEntry)
+		assert.Equal(t, "100000000000000000000", typed.Quantity.String())
+		assert.Equal(t, "25/2", typed.Amount.String())
+		assert.Nil(t, typed.Rate)
+	})
+
+	t.Run("compares with configured decimal rule", func(t *testing.T) {
+		actual := &ledgerEntry{Amount: big.NewRat(12499, 1000), Rate: big.NewFloat(0.25)}
+		table := buildTable([][]string{
+			{"Amount", "12.50"},
+			{"Rate", "0.250"},
+		})
+
+		err := NewDefault().CompareToInstance(actual, table)
+		assert.EqualError(t, err, `comparison failed:
+- Amount: expected 12.50, but got 12.499`)
+
+		assist := NewDefault()
+		assist.SetDecimalRule(defaults.DecimalRounded)
+		assert.NoError(t, assist.CompareToInstance(actual, table))
+	})
+}
+
+func TestFloatTolerance(t *testing.T) {
+	tenth := 0.1
+	actual := &measurement{Amount: tenth + 0.2}
+
+	err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Amount", "0.3"}}))
+	assert.EqualError(t, err, `comparison failed:
+- Amount: expected 0.3, but got 0.30000000000000004`)
+
+	assist := NewDefault()
+	assist.SetFloatEpsilon(1e-9)
+	assert.NoError(t, assist.CompareToInstance(actual, buildTable([][]string{{"Amount", "0.3"}})))
+
+	assist = NewDefault()
+	assist.SetFloatPrecisionInference(true)
+	assert.NoError(t, assist.CompareToInstance(&measurement{Amount: 3.1415}, buildTable([][]string{{"Amount", "3.14"}})))
+}
+
+type subscriber struct {
+	Email string `assist:",string=fold|trim"`
+	Code  string
+}
+
+func TestStringModes(t *testing.T) {
+	actual := &subscriber{Email: "John@Example.com ", Code: "ab-1"}
+
+	t.Run("compares fields with their tag's mode", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"Email", "john@example.com"},
+			{"Code", "AB-1"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Code: expected AB-1, but got ab-1`)
+	})
+
+	t.Run("compares with the assist's mode", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetStringMode(defaults.StringFold)
+
+		assert.NoError(t, assist.CompareToInstance(actual, buildTable([][]string{{"Code", "AB-1"}})))
+	})
+
+	t.Run("compares with the cell's mode", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Code", "(prefix) ab"}}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports invalid modes", func(t *testing.T) {
+		type invalid struct {
+			Name string `assist:",string=loose"`
+		}
+
+		err := NewDefault().CompareToInstance(&invalid{}, buildTable([][]string{{"Name", "x"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Name: unknown string mode loose, expected one of collapse, exact, fold, nfc, nfkc, prefix, suffix, trim`)
+	})
+}
+
+type reminder struct {
+	Due     time.Time
+	Created *time.Time
+}
+
+func TestClock(t *testing.T) {
+	now := time.Date(2020, 11, 5, 16, 1, 54, 0, time.UTC)
+	assist := NewDefault()
+	assist.SetClock(func() time.Time { return now })
+
+	t.Run("creates instance with relative times", func(t *testing.T) {
+		result, err := assist.CreateInstance(new(reminder), buildTable([][]string{
+			{"Due", "tomorrow 09:00"},
+			{"Created", "now"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*reminder)
+		assert.Equal(t, time.Date(2020, 11, 6, 9, 0, 0, 0, time.UTC), typed.Due)
+		assert.Equal(t, now, *typed.Created)
+	})
+
+	t.Run("compares instance with relative times", func(t *testing.T) {
+		err := assist.CompareToInstance(&reminder{Due: now.Add(-48 * time.Hour)}, buildTable([][]string{
+			{"Due", "2 days ago"},
+			{"Created", "<nil>"},
+		}))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestTimeConfiguration(t *testing.T) {
+	t.Run("uses custom layouts and location", func(t *testing.T) {
+		location := time.FixedZone("BRT", -3*3600)
+		assist := NewDefault()
+		assist.SetTimeLayouts("02/01/2006 15:04")
+		assist.SetTimeLocation(location)
+
+		result, err := assist.CreateInstance(new(reminder), buildTable([][]string{{"Due", "05/11/2020 09:30"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, time.Date(2020, 11, 5, 9, 30, 0, 0, location), result.(*reminder).Due)
+	})
+
+	t.Run("compares with tolerance", func(t *testing.T) {
+		now := time.Date(2020, 11, 5, 16, 1, 54, 0, time.UTC)
+		assist := NewDefault()
+		assist.SetClock(func() time.Time { return now })
+		assist.SetTimeTolerance(time.Second)
+
+		err := assist.CompareToInstance(&reminder{Due: now.Add(500 * time.Millisecond)}, buildTable([][]string{{"Due", "now"}}))
+		assert.NoError(t, err)
+	})
+}
+
+type endpoint struct {
+	IP      net.IP
+	Subnet  *net.IPNet
+	URL     *url.URL
+	Contact *mail.Address
+	MAC     net.HardwareAddr
+}
+
+func TestNetworkTypes(t *testing.T) {
+	table := buildTable([][]string{
+		{"IP", "192.168.0.10"},
+		{"Subnet", "192.168.0.0/24"},
+		{"URL", "https://example.com/health?verbose=1&format=json"},
+		{"Contact", "Ops <ops@example.com>"},
+		{"MAC", "00:1a:2b:3c:4d:5e"},
+	})
+
+	t.Run("creates and compares instance", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(endpoint), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*endpoint)
+		assert.Equal(t, "192.168.0.10", typed.IP.String())
+		assert.Equal(t, "192.168.0.0/24", typed.Subnet.String())
+		assert.Equal(t, "/health", typed.URL.Path)
+		assert.Equal(t, "ops@example.com", typed.Contact.Address)
+		assert.Equal(t, "00:1a:2b:3c:4d:5e", typed.MAC.String())
+
+		assert.NoError(t, NewDefault().CompareToInstance(typed, table))
+	})
+
+	t.Run("creates and compares value fields", func(t *testing.T) {
+		type route struct {
+			Subnet  net.IPNet
+			Contact mail.Address
+		}
+
+		table := buildTable([][]string{
+			{"Subnet", "10.0.0.0/8"},
+			{"Contact", "Ops <ops@example.com>"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(route), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*route)
+		assert.Equal(t, "10.0.0.0/8", typed.Subnet.String())
+		assert.Equal(t, "ops@example.com", typed.Contact.Address)
+		assert.NoError(t, NewDefault().CompareToInstance(typed, table))
+
+		err = NewDefault().CompareToInstance(typed, buildTable([][]string{{"Subnet", "10.0.0.0/16"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Subnet: expected 10.0.0.0/16, but got 10.0.0.0/8`)
+	})
+}
+
 func TestCreateSlice(t *testing.T) {
 	t.Run("successfully", func(t *testing.T) {
 		table := buildTable([][]string{
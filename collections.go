@@ -0,0 +1,507 @@
+package assistdog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultListDelimiter separates the elements of slice and array fields in a cell,
+// unless replaced with SetListDelimiter.
+const DefaultListDelimiter = ','
+
+// DefaultMapDelimiters separate the entries of map fields in a cell, and the key from
+// the value within each entry, unless replaced with SetMapDelimiters.
+const (
+	DefaultMapEntryDelimiter    = ';'
+	DefaultMapKeyValueDelimiter = '='
+)
+
+var bytesType = reflect.TypeOf([]byte{})
+
+// SliceOrder defines how the elements of slice and array fields are compared.
+type SliceOrder int
+
+const (
+	// OrderedSlices compares elements index by index.
+	OrderedSlices SliceOrder = iota
+	// UnorderedSlices compares elements regardless of their position.
+	UnorderedSlices
+)
+
+// SetListDelimiter replaces the character that separates the elements of slice and array fields in a cell.
+// Elements that contain the delimiter can be wrapped in double quotes, or the delimiter can be escaped
+// with a backslash.
+func (a *Assist) SetListDelimiter(delimiter rune) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.listDelimiter = delimiter
+}
+
+// SetSliceOrder defines how the elements of slice and array fields are compared.
+// By default, elements are compared index by index.
+func (a *Assist) SetSliceOrder(order SliceOrder) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.sliceOrder = order
+}
+
+// SetMapDelimiters replaces the characters that separate the entries of map fields in a cell,
+// and the key from the value within each entry, as in `env=prod; team=core`.
+func (a *Assist) SetMapDelimiters(entry, keyValue rune) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.mapEntryDelimiter = entry
+	a.mapKeyValueDelimiter = keyValue
+}
+
+func (a *Assist) currentListDelimiter() rune {
+	if a.listDelimiter == 0 {
+		return DefaultListDelimiter
+	}
+
+	return a.listDelimiter
+}
+
+// lookupListParser returns a parser for slice and array types that splits the cell and
+// parses each element with the parser of the element type.
+// It must be called with the lock held.
+func (a *Assist) lookupListParser(tp reflect.Type) (ParseFunc, bool) {
+	if tp.Kind() != reflect.Slice && tp.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	// Only lists of byte itself convert to and from []byte; named byte types go element by element.
+	if tp.Elem() == bytesType.Elem() {
+		if p, ok := a.parsers[bytesType]; ok {
+			return bytesParser(tp, p), true
+		}
+	}
+
+	elemParse, ok := a.resolveParser(tp.Elem())
+	if !ok {
+		return nil, false
+	}
+
+	delimiter := a.currentListDelimiter()
+	return func(raw string) (interface{}, error) {
+		elems, err := splitList(raw, delimiter)
+		if err != nil {
+			return nil, err
+		}
+
+		var result reflect.Value
+		if tp.Kind() == reflect.Array {
+			if len(elems) != tp.Len() {
+				return nil, fmt.Errorf("expected %v elements, but got %v", tp.Len(), len(elems))
+			}
+
+			result = reflect.New(tp).Elem()
+		} else {
+			result = reflect.MakeSlice(tp, len(elems), len(elems))
+		}
+
+		for i, elem := range elems {
+			parsed, err := elemParse(elem)
+			if err != nil {
+				return nil, fmt.Errorf("element %v: %v", i, err)
+			}
+
+			setValue(result.Index(i), parsed)
+		}
+
+		return result.Interface(), nil
+	}, true
+}
+
+// lookupListComparer returns a comparer for slice and array types that splits the cell and
+// compares each element with the comparer of the element type.
+// It must be called with the lock held.
+func (a *Assist) lookupListComparer(tp reflect.Type) (CompareFunc, bool) {
+	if tp.Kind() != reflect.Slice && tp.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	if tp.Elem() == bytesType.Elem() {
+		if c, ok := a.comparers[bytesType]; ok {
+			return bytesComparer(c), true
+		}
+	}
+
+	elemCompare, ok := a.resolveComparer(tp.Elem())
+	if !ok {
+		return nil, false
+	}
+
+	delimiter := a.currentListDelimiter()
+	order := a.sliceOrder
+	return func(raw string, actual interface{}) error {
+		elems, err := splitList(raw, delimiter)
+		if err != nil {
+			return err
+		}
+
+		av := reflect.ValueOf(actual)
+		if order == UnorderedSlices {
+			return compareUnordered(elems, av, elemCompare)
+		}
+
+		return compareOrdered(elems, av, elemCompare)
+	}, true
+}
+
+func (a *Assist) currentMapDelimiters() (rune, rune) {
+	entry, keyValue := a.mapEntryDelimiter, a.mapKeyValueDelimiter
+	if entry == 0 {
+		entry = DefaultMapEntryDelimiter
+	}
+
+	if keyValue == 0 {
+		keyValue = DefaultMapKeyValueDelimiter
+	}
+
+	return entry, keyValue
+}
+
+// lookupMapParser returns a parser for map types that splits the cell into entries and
+// parses each key and value with the parsers of the key and value types.
+// It must be called with the lock held.
+func (a *Assist) lookupMapParser(tp reflect.Type) (ParseFunc, bool) {
+	if tp.Kind() != reflect.Map {
+		return nil, false
+	}
+
+	keyParse, ok := a.resolveParser(tp.Key())
+	if !ok {
+		return nil, false
+	}
+
+	valueParse, ok := a.resolveParser(tp.Elem())
+	if !ok {
+		return nil, false
+	}
+
+	entryDelimiter, keyValueDelimiter := a.currentMapDelimiters()
+	return func(raw string) (interface{}, error) {
+		entries, err := splitMap(raw, entryDelimiter, keyValueDelimiter)
+		if err != nil {
+			return nil, err
+		}
+
+		result := reflect.MakeMapWithSize(tp, len(entries))
+		for _, entry := range entries {
+			key, err := keyParse(entry[0])
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %v", entry[0], err)
+			}
+
+			value, err := valueParse(entry[1])
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %v", entry[0], err)
+			}
+
+			kv := reflect.New(tp.Key()).Elem()
+			setValue(kv, key)
+			vv := reflect.New(tp.Elem()).Elem()
+			setValue(vv, value)
+			result.SetMapIndex(kv, vv)
+		}
+
+		return result.Interface(), nil
+	}, true
+}
+
+// lookupMapComparer returns a comparer for map types that reports missing, unexpected and
+// differing keys. Expected keys are parsed with the parser of the key type to find the actual
+// entries, whose values are then compared with the comparer of the value type.
+// It must be called with the lock held.
+func (a *Assist) lookupMapComparer(tp reflect.Type) (CompareFunc, bool) {
+	if tp.Kind() != reflect.Map {
+		return nil, false
+	}
+
+	keyParse, ok := a.resolveParser(tp.Key())
+	if !ok {
+		return nil, false
+	}
+
+	valueCompare, ok := a.resolveComparer(tp.Elem())
+	if !ok {
+		return nil, false
+	}
+
+	entryDelimiter, keyValueDelimiter := a.currentMapDelimiters()
+	return func(raw string, actual interface{}) error {
+		entries, err := splitMap(raw, entryDelimiter, keyValueDelimiter)
+		if err != nil {
+			return err
+		}
+
+		av := reflect.ValueOf(actual)
+		seen := map[interface{}]bool{}
+		missing := []string{}
+		differing := []string{}
+		for _, entry := range entries {
+			key, err := keyParse(entry[0])
+			if err != nil {
+				return fmt.Errorf("key %v: %v", entry[0], err)
+			}
+
+			kv := reflect.New(tp.Key()).Elem()
+			setValue(kv, key)
+			vv := av.MapIndex(kv)
+			if !vv.IsValid() {
+				missing = append(missing, entry[0])
+				continue
+			}
+
+			seen[kv.Interface()] = true
+			if err := valueCompare(entry[1], vv.Interface()); err != nil {
+				differing = append(differing, fmt.Sprintf("key %v: %v", entry[0], err))
+			}
+		}
+
+		unexpected := []string{}
+		for _, kv := range av.MapKeys() {
+			if !seen[kv.Interface()] {
+				unexpected = append(unexpected, fmt.Sprint(describe(kv)))
+			}
+		}
+
+		sort.Strings(unexpected)
+
+		errs := []string{}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Sprintf("missing keys [%v]", strings.Join(missing, ", ")))
+		}
+
+		if len(unexpected) > 0 {
+			errs = append(errs, fmt.Sprintf("unexpected keys [%v]", strings.Join(unexpected, ", ")))
+		}
+
+		errs = append(errs, differing...)
+		if len(errs) > 0 {
+			return fmt.Errorf("%v", strings.Join(errs, "; "))
+		}
+
+		return nil
+	}, true
+}
+
+// bytesParser adapts the parser registered for []byte to named byte slices and byte arrays,
+// such as [32]byte, which must receive exactly as many bytes as their length.
+func bytesParser(tp reflect.Type, parse ParseFunc) ParseFunc {
+	return func(raw string) (interface{}, error) {
+		parsed, err := parse(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		b := reflect.ValueOf(parsed)
+		if tp.Kind() == reflect.Slice {
+			return b.Convert(tp).Interface(), nil
+		}
+
+		if b.Len() != tp.Len() {
+			return nil, fmt.Errorf("expected %v bytes, but got %v", tp.Len(), b.Len())
+		}
+
+		result := reflect.New(tp).Elem()
+		reflect.Copy(result, b)
+		return result.Interface(), nil
+	}
+}
+
+// bytesComparer adapts the comparer registered for []byte to named byte slices and byte arrays.
+func bytesComparer(compare CompareFunc) CompareFunc {
+	return func(raw string, actual interface{}) error {
+		av := reflect.ValueOf(actual)
+		b := make([]byte, av.Len())
+		reflect.Copy(reflect.ValueOf(b), av)
+		return compare(raw, b)
+	}
+}
+
+func compareOrdered(expected []string, actual reflect.Value, compare CompareFunc) error {
+	if len(expected) != actual.Len() {
+		return fmt.Errorf("expected %v elements, but got %v: %v", len(expected), actual.Len(), describe(actual))
+	}
+
+	errs := []string{}
+	for i, elem := range expected {
+		if err := compare(elem, actual.Index(i).Interface()); err != nil {
+			errs = append(errs, fmt.Sprintf("element %v: %v", i, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func compareUnordered(expected []string, actual reflect.Value, compare CompareFunc) error {
+	matched := make([]bool, actual.Len())
+	missing := []string{}
+	for _, elem := range expected {
+		found := false
+		for i := 0; i < actual.Len(); i++ {
+			if matched[i] {
+				continue
+			}
+
+			if compare(elem, actual.Index(i).Interface()) == nil {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, elem)
+		}
+	}
+
+	unexpected := []string{}
+	for i, ok := range matched {
+		if !ok {
+			unexpected = append(unexpected, fmt.Sprint(describe(actual.Index(i))))
+		}
+	}
+
+	errs := []string{}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Sprintf("missing elements [%v]", strings.Join(missing, ", ")))
+	}
+
+	if len(unexpected) > 0 {
+		errs = append(errs, fmt.Sprintf("unexpected elements [%v]", strings.Join(unexpected, ", ")))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// splitList splits a cell into elements separated by a delimiter. Whitespace around elements
+// is ignored, double quotes preserve delimiters and whitespace, and a backslash escapes the
+// character that follows it. An empty cell has no elements.
+func splitList(raw string, delimiter rune) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return []string{}, nil
+	}
+
+	parts, err := splitRaw(raw, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	elems := make([]string, len(parts))
+	for i, part := range parts {
+		elems[i] = unquote(part)
+	}
+
+	return elems, nil
+}
+
+// splitMap splits a cell into key and value pairs, following the same quoting and
+// escaping rules as splitList for both entries and their keys and values.
+func splitMap(raw string, entryDelimiter, keyValueDelimiter rune) ([][2]string, error) {
+	entries, err := splitRaw(raw, entryDelimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := [][2]string{}
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts, err := splitRaw(entry, keyValueDelimiter)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected entry %v to be in the form key%cvalue", strings.TrimSpace(entry), keyValueDelimiter)
+		}
+
+		result = append(result, [2]string{unquote(parts[0]), unquote(parts[1])})
+	}
+
+	return result, nil
+}
+
+// splitRaw splits a cell on a delimiter that is neither quoted nor escaped,
+// leaving the quotes and escapes in place for the parts to be unquoted later.
+func splitRaw(raw string, delimiter rune) ([]string, error) {
+	parts := []string{}
+	current := []rune{}
+	quoted := false
+	escaped := false
+	for _, r := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == delimiter:
+			parts = append(parts, string(current))
+			current = current[:0]
+			continue
+		}
+
+		current = append(current, r)
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %v", raw)
+	}
+
+	return append(parts, string(current)), nil
+}
+
+// unquote removes the whitespace around a part of a cell, along with its quotes and escapes.
+func unquote(part string) string {
+	current := []rune{}
+	keep := 0
+	quoted := false
+	escaped := false
+	for _, r := range part {
+		switch {
+		case escaped:
+			current = append(current, r)
+			keep = len(current)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			keep = len(current)
+		case quoted:
+			current = append(current, r)
+			keep = len(current)
+		case r == ' ' || r == '\t':
+			if len(current) > 0 {
+				current = append(current, r)
+			}
+		default:
+			current = append(current, r)
+			keep = len(current)
+		}
+	}
+
+	if escaped {
+		current = append(current, '\\')
+		keep = len(current)
+	}
+
+	return string(current[:keep])
+}
@@ -0,0 +1,289 @@
+package assistdog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type article struct {
+	Tags   []string
+	Scores [3]int
+	Owners []*string
+}
+
+func TestSplitList(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{name: "empty cell", raw: "  ", expected: []string{}},
+		{name: "single element", raw: "a", expected: []string{"a"}},
+		{name: "trims elements", raw: " a , b c ,d ", expected: []string{"a", "b c", "d"}},
+		{name: "keeps empty elements", raw: "a,,b", expected: []string{"a", "", "b"}},
+		{name: "quoted delimiter", raw: `"a, b", c`, expected: []string{"a, b", "c"}},
+		{name: "quoted whitespace", raw: `" a ",b`, expected: []string{" a ", "b"}},
+		{name: "escaped delimiter", raw: `a\,b,c`, expected: []string{"a,b", "c"}},
+		{name: "escaped quote", raw: `say \"hi\"`, expected: []string{`say "hi"`}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := splitList(tc.raw, ',')
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+
+	t.Run("returns error for unterminated quote", func(t *testing.T) {
+		_, err := splitList(`"a, b`, ',')
+
+		require.EqualError(t, err, `unterminated quote in "a, b`)
+	})
+}
+
+func TestCreateInstanceWithLists(t *testing.T) {
+	t.Run("successfully", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Tags", "go, testing, \"a, b\""},
+			{"Scores", "1, 2, 3"},
+			{"Owners", "john, <nil>"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(article), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*article)
+		assert.Equal(t, []string{"go", "testing", "a, b"}, typed.Tags)
+		assert.Equal(t, [3]int{1, 2, 3}, typed.Scores)
+		require.Len(t, typed.Owners, 2)
+		assert.Equal(t, "john", *typed.Owners[0])
+		assert.Nil(t, typed.Owners[1])
+	})
+
+	t.Run("with custom delimiter", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetListDelimiter(';')
+
+		result, err := assist.CreateInstance(new(article), buildTable([][]string{{"Tags", "a,b; c"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []string{"a,b", "c"}, result.(*article).Tags)
+	})
+
+	t.Run("with invalid element", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(article), buildTable([][]string{{"Scores", "1, x, 3"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.article:
+- Scores: element 1: strconv.Atoi: parsing "x": invalid syntax`)
+	})
+
+	t.Run("with wrong number of array elements", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(article), buildTable([][]string{{"Scores", "1, 2"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.article:
+- Scores: expected 3 elements, but got 2`)
+	})
+}
+
+func TestCompareToInstanceWithLists(t *testing.T) {
+	actual := &article{
+		Tags:   []string{"go", "testing"},
+		Scores: [3]int{1, 2, 3},
+	}
+
+	t.Run("successfully", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Tags", "go, testing"},
+			{"Scores", "1, 2, 3"},
+			{"Owners", "<nil>"},
+		})
+
+		err := NewDefault().CompareToInstance(actual, table)
+		assert.NoError(t, err)
+	})
+
+	t.Run("compares nil lists and maps with empty cells", func(t *testing.T) {
+		table := buildTable([][]string{{"Tags", ""}})
+
+		result, err := NewDefault().CreateInstance(new(article), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []string{}, result.(*article).Tags)
+		assert.NoError(t, NewDefault().CompareToInstance(&article{}, table))
+		assert.NoError(t, NewDefault().CompareToInstance(&resource{}, buildTable([][]string{{"Labels", ""}})))
+		assert.EqualError(t, NewDefault().CompareToInstance(&article{}, buildTable([][]string{{"Tags", "go"}})), `comparison failed:
+- Tags: expected 1 elements, but got 0: []`)
+	})
+
+	t.Run("reports differing elements by index", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Scores", "1, 5, 6"},
+		})
+
+		err := NewDefault().CompareToInstance(actual, table)
+		assert.EqualError(t, err, `comparison failed:
+- Scores: element 1: expected 5, but got 2; element 2: expected 6, but got 3`)
+	})
+
+	t.Run("reports different lengths", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Tags", "go"},
+		})
+
+		err := NewDefault().CompareToInstance(actual, table)
+		assert.EqualError(t, err, `comparison failed:
+- Tags: expected 1 elements, but got 2: [go testing]`)
+	})
+
+	t.Run("compares unordered", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetSliceOrder(UnorderedSlices)
+
+		err := assist.CompareToInstance(actual, buildTable([][]string{{"Tags", "testing, go"}}))
+		assert.NoError(t, err)
+
+		err = assist.CompareToInstance(actual, buildTable([][]string{{"Tags", "testing, rust"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Tags: missing elements [rust]; unexpected elements [go]`)
+	})
+}
+
+type resource struct {
+	Labels map[string]string
+	Limits map[string]int
+}
+
+func TestSplitMap(t *testing.T) {
+	t.Run("splits entries", func(t *testing.T) {
+		res, err := splitMap(`env=prod; team = core ;"a;b"="x=y";`, ';', '=')
+
+		require.NoError(t, err)
+		assert.Equal(t, [][2]string{{"env", "prod"}, {"team", "core"}, {"a;b", "x=y"}}, res)
+	})
+
+	t.Run("returns error for entry without value", func(t *testing.T) {
+		_, err := splitMap("env=prod; team", ';', '=')
+
+		require.EqualError(t, err, "expected entry team to be in the form key=value")
+	})
+}
+
+func TestMaps(t *testing.T) {
+	t.Run("creates map fields", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Labels", "env=prod; team=core"},
+			{"Limits", "cpu=2; memory=512"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(resource), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &resource{
+			Labels: map[string]string{"env": "prod", "team": "core"},
+			Limits: map[string]int{"cpu": 2, "memory": 512},
+		}, result)
+	})
+
+	t.Run("with custom delimiters", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetMapDelimiters(',', ':')
+
+		result, err := assist.CreateInstance(new(resource), buildTable([][]string{{"Limits", "cpu: 2, memory: 512"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, result.(*resource).Limits)
+	})
+
+	t.Run("with invalid value", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(resource), buildTable([][]string{{"Limits", "cpu=two"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.resource:
+- Limits: key cpu: strconv.Atoi: parsing "two": invalid syntax`)
+	})
+
+	t.Run("compares map fields", func(t *testing.T) {
+		actual := &resource{Labels: map[string]string{"env": "prod", "team": "core"}}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Labels", "team=core; env=prod"}}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports missing, unexpected and differing keys", func(t *testing.T) {
+		actual := &resource{Limits: map[string]int{"cpu": 4, "disk": 10, "gpu": 1}}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Limits", "cpu=2; memory=512"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Limits: missing keys [memory]; unexpected keys [disk, gpu]; key cpu: expected 2, but got 4`)
+	})
+}
+
+type digest [4]byte
+
+type octet uint8
+
+type signedToken struct {
+	Token     []byte
+	Hash      digest
+	Signature [4]byte
+	Octets    []octet
+}
+
+func TestBinaryFields(t *testing.T) {
+	t.Run("creates byte slices and arrays", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(signedToken), buildTable([][]string{
+			{"Token", "base64:CgsM"},
+			{"Hash", "hex:deadbeef"},
+			{"Signature", "abcd"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &signedToken{
+			Token:     []byte{0x0a, 0x0b, 0x0c},
+			Hash:      digest{0xde, 0xad, 0xbe, 0xef},
+			Signature: [4]byte{'a', 'b', 'c', 'd'},
+		}, result)
+	})
+
+	t.Run("reports wrong array length", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(signedToken), buildTable([][]string{{"Hash", "hex:dead"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.signedToken:
+- Hash: expected 4 bytes, but got 2`)
+	})
+
+	t.Run("compares byte arrays", func(t *testing.T) {
+		actual := &signedToken{Hash: digest{0xde, 0xad, 0xbe, 0xef}}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Hash", "hex:deadbeaf"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Hash: expected hex:deadbeaf, but got hex:deadbeef (first difference at byte 3, expected 4 bytes, got 4)`)
+	})
+
+	t.Run("parses lists of named byte types element by element", func(t *testing.T) {
+		table := buildTable([][]string{{"Octets", "10, 11, 12"}})
+
+		result, err := NewDefault().CreateInstance(new(signedToken), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []octet{10, 11, 12}, result.(*signedToken).Octets)
+		assert.NoError(t, NewDefault().CompareToInstance(result, table))
+	})
+}
@@ -1,22 +1,192 @@
 package defaults
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DecimalRule defines how decimal values written in a table are compared to *big.Float and *big.Rat values.
+type DecimalRule int
+
+const (
+	// DecimalValue compares numeric values, so 12.50 matches 12.5 but not 12.499.
+	DecimalValue DecimalRule = iota
+	// DecimalRounded rounds the actual value half away from zero to the number of decimals
+	// written in the table, so 12.50 matches 12.499 but not 12.49.
+	// Values written as fractions or in exponent notation are compared by DecimalValue.
+	DecimalRounded
+)
+
+// DecimalComparer compares decimal values written in a table to *big.Float and *big.Rat values.
+type DecimalComparer struct {
+	Rule DecimalRule
+}
+
+// FloatComparer compares values written in a table to float32 and float64 values.
+// Besides plain numbers, cells can specify a tolerance explicitly, as in "3.14 ± 0.01" or "3.14 +/- 0.01",
+// or ask for one inferred from the digits written by prefixing them with "~", so that "~3.14" matches
+// values from 3.135 to 3.145. NaN only matches NaN, and infinities only match infinities of the same sign,
+// regardless of the tolerance.
+type FloatComparer struct {
+	// Epsilon is the tolerance for cells that don't specify one. Zero requires exact equality.
+	Epsilon float64
+	// InferPrecision infers the tolerance of every cell from the digits written, as if they were prefixed with "~".
+	InferPrecision bool
+}
+
+// StringMode defines how string values written in a table are compared to actual strings.
+// Modes can be combined, as in StringFold|StringTrim.
+type StringMode int
+
+const (
+	// StringFold compares strings case-insensitively, using Unicode case folding.
+	StringFold StringMode = 1 << iota
+	// StringTrim ignores leading and trailing whitespace.
+	StringTrim
+	// StringCollapseSpace ignores leading and trailing whitespace and treats runs of whitespace as a single space.
+	StringCollapseSpace
+	// StringNFC compares strings in Unicode normalization form C, so composed and decomposed accents match.
+	StringNFC
+	// StringNFKC compares strings in Unicode normalization form KC, which also matches compatibility
+	// characters such as ligatures and full-width letters with their plain counterparts.
+	StringNFKC
+	// StringPrefix matches actual strings that start with the expected value.
+	StringPrefix
+	// StringSuffix matches actual strings that end with the expected value.
+	StringSuffix
+
+	// StringExact compares strings byte by byte.
+	StringExact StringMode = 0
 )
 
+var stringModeNames = map[string]StringMode{
+	"exact":    StringExact,
+	"fold":     StringFold,
+	"trim":     StringTrim,
+	"collapse": StringCollapseSpace,
+	"nfc":      StringNFC,
+	"nfkc":     StringNFKC,
+	"prefix":   StringPrefix,
+	"suffix":   StringSuffix,
+}
+
+// StringComparer compares values written in a table to strings following its mode.
+// A cell can choose its own modes by starting with their names in parentheses, as in "(fold, trim) Hello",
+// which replace the comparer's mode for that cell.
+type StringComparer struct {
+	Mode StringMode
+}
+
+// ParseStringMode parses a list of mode names separated by commas or pipes, such as "fold|trim".
+// The names are exact, fold, trim, collapse, nfc, nfkc, prefix and suffix.
+func ParseStringMode(raw string) (StringMode, error) {
+	mode := StringExact
+	for _, name := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '|' }) {
+		m, ok := stringModeNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			names := make([]string, 0, len(stringModeNames))
+			for n := range stringModeNames {
+				names = append(names, n)
+			}
+
+			sort.Strings(names)
+			return 0, fmt.Errorf("unknown string mode %v, expected one of %v", strings.TrimSpace(name), strings.Join(names, ", "))
+		}
+
+		mode |= m
+	}
+
+	return mode, nil
+}
+
 func CompareString(raw string, actual interface{}) error {
+	return StringComparer{}.Compare(raw, actual)
+}
+
+// Compare compares a raw value to an actual string following the mode of the cell or the comparer.
+// When the difference involves invisible characters, such as trailing or non-breaking spaces,
+// both values are quoted and the first differing character is described.
+func (c StringComparer) Compare(raw string, actual interface{}) error {
 	as, ok := actual.(string)
 	if !ok {
 		return fmt.Errorf("%v is not a string", actual)
 	}
 
-	if as != raw {
-		return fmt.Errorf("expected %v, but got %v", raw, as)
+	mode, expected := c.Mode, raw
+	if cellMode, rest, ok := cellStringMode(raw); ok {
+		mode, expected = cellMode, rest
 	}
 
-	return nil
+	ne, na := mode.normalize(expected), mode.normalize(as)
+	var matches bool
+	switch {
+	case mode&(StringPrefix|StringSuffix) == StringPrefix|StringSuffix:
+		matches = strings.HasPrefix(na, ne) && strings.HasSuffix(na, ne)
+	case mode&StringPrefix != 0:
+		matches = strings.HasPrefix(na, ne)
+	case mode&StringSuffix != 0:
+		matches = strings.HasSuffix(na, ne)
+	default:
+		matches = na == ne
+	}
+
+	if matches {
+		return nil
+	}
+
+	expectation := map[StringMode]string{
+		StringPrefix:                "to start with ",
+		StringSuffix:                "to end with ",
+		StringPrefix | StringSuffix: "to start and end with ",
+	}[mode&(StringPrefix|StringSuffix)]
+
+	if detail, ok := invisibleDifference(ne, na); ok {
+		return fmt.Errorf("expected %v%v, but got %v (%v)", expectation, quoteVisible(expected), quoteVisible(as), detail)
+	}
+
+	return fmt.Errorf("expected %v%v, but got %v", expectation, expected, as)
+}
+
+// normalize applies the normalizations of a mode to a string, leaving prefix and suffix matching to the caller.
+func (m StringMode) normalize(s string) string {
+	switch {
+	case m&StringNFKC != 0:
+		s = norm.NFKC.String(s)
+	case m&StringNFC != 0:
+		s = norm.NFC.String(s)
+	}
+
+	switch {
+	case m&StringCollapseSpace != 0:
+		s = strings.Join(strings.Fields(s), " ")
+	case m&StringTrim != 0:
+		s = strings.TrimSpace(s)
+	}
+
+	if m&StringFold != 0 {
+		s = cases.Fold().String(s)
+	}
+
+	return s
 }
 
 func CompareInt(raw string, actual interface{}) error {
@@ -25,7 +195,7 @@ func CompareInt(raw string, actual interface{}) error {
 		return fmt.Errorf("%v is not an int", actual)
 	}
 
-	ei, err := strconv.Atoi(raw)
+	ei, err := ParseInt(raw)
 	if err != nil {
 		return err
 	}
@@ -37,20 +207,900 @@ func CompareInt(raw string, actual interface{}) error {
 	return nil
 }
 
+func CompareInt8(raw string, actual interface{}) error {
+	ai, ok := actual.(int8)
+	if !ok {
+		return fmt.Errorf("%v is not an int8", actual)
+	}
+
+	return compareSigned(raw, int64(ai), 8, "int8")
+}
+
+func CompareInt16(raw string, actual interface{}) error {
+	ai, ok := actual.(int16)
+	if !ok {
+		return fmt.Errorf("%v is not an int16", actual)
+	}
+
+	return compareSigned(raw, int64(ai), 16, "int16")
+}
+
+func CompareInt32(raw string, actual interface{}) error {
+	ai, ok := actual.(int32)
+	if !ok {
+		return fmt.Errorf("%v is not an int32", actual)
+	}
+
+	return compareSigned(raw, int64(ai), 32, "int32")
+}
+
+func CompareInt64(raw string, actual interface{}) error {
+	ai, ok := actual.(int64)
+	if !ok {
+		return fmt.Errorf("%v is not an int64", actual)
+	}
+
+	return compareSigned(raw, ai, 64, "int64")
+}
+
+func CompareUint(raw string, actual interface{}) error {
+	ai, ok := actual.(uint)
+	if !ok {
+		return fmt.Errorf("%v is not a uint", actual)
+	}
+
+	return compareUnsigned(raw, uint64(ai), strconv.IntSize, "uint")
+}
+
+func CompareUint8(raw string, actual interface{}) error {
+	ai, ok := actual.(uint8)
+	if !ok {
+		return fmt.Errorf("%v is not a uint8", actual)
+	}
+
+	return compareUnsigned(raw, uint64(ai), 8, "uint8")
+}
+
+func CompareUint16(raw string, actual interface{}) error {
+	ai, ok := actual.(uint16)
+	if !ok {
+		return fmt.Errorf("%v is not a uint16", actual)
+	}
+
+	return compareUnsigned(raw, uint64(ai), 16, "uint16")
+}
+
+func CompareUint32(raw string, actual interface{}) error {
+	ai, ok := actual.(uint32)
+	if !ok {
+		return fmt.Errorf("%v is not a uint32", actual)
+	}
+
+	return compareUnsigned(raw, uint64(ai), 32, "uint32")
+}
+
+func CompareUint64(raw string, actual interface{}) error {
+	ai, ok := actual.(uint64)
+	if !ok {
+		return fmt.Errorf("%v is not a uint64", actual)
+	}
+
+	return compareUnsigned(raw, ai, 64, "uint64")
+}
+
+func CompareFloat32(raw string, actual interface{}) error {
+	return FloatComparer{}.CompareFloat32(raw, actual)
+}
+
+func CompareFloat64(raw string, actual interface{}) error {
+	return FloatComparer{}.CompareFloat64(raw, actual)
+}
+
+// CompareFloat32 compares a raw value to an actual float32 within the tolerance of the cell or the comparer.
+func (c FloatComparer) CompareFloat32(raw string, actual interface{}) error {
+	ai, ok := actual.(float32)
+	if !ok {
+		return fmt.Errorf("%v is not a float32", actual)
+	}
+
+	return c.compare(raw, float64(ai), 32, "float32")
+}
+
+// CompareFloat64 compares a raw value to an actual float64 within the tolerance of the cell or the comparer.
+func (c FloatComparer) CompareFloat64(raw string, actual interface{}) error {
+	ai, ok := actual.(float64)
+	if !ok {
+		return fmt.Errorf("%v is not a float64", actual)
+	}
+
+	return c.compare(raw, ai, 64, "float64")
+}
+
+func (c FloatComparer) compare(raw string, actual float64, bitSize int, typeName string) error {
+	value, tolerance, err := c.tolerance(raw)
+	if err != nil {
+		return err
+	}
+
+	expected, err := parseFloat(value, bitSize, typeName)
+	if err != nil {
+		return err
+	}
+
+	finite := !math.IsNaN(expected) && !math.IsInf(expected, 0) && !math.IsNaN(actual) && !math.IsInf(actual, 0)
+	switch {
+	case !finite:
+		if expected == actual || math.IsNaN(expected) && math.IsNaN(actual) {
+			return nil
+		}
+	case math.Abs(expected-actual) <= tolerance:
+		return nil
+	}
+
+	if !finite || tolerance == 0 {
+		return fmt.Errorf("expected %v, but got %v", formatFloat(expected, bitSize), formatFloat(actual, bitSize))
+	}
+
+	return fmt.Errorf("expected %v ± %v, but got %v, which is %v off", formatFloat(expected, bitSize),
+		formatFloat(tolerance, 64), formatFloat(actual, bitSize), strconv.FormatFloat(math.Abs(expected-actual), 'g', 6, 64))
+}
+
+// tolerance splits a raw value into the number and the tolerance to compare it with.
+func (c FloatComparer) tolerance(raw string) (string, float64, error) {
+	trimmed := strings.TrimSpace(raw)
+	for _, separator := range []string{"±", "+/-"} {
+		i := strings.Index(trimmed, separator)
+		if i < 0 {
+			continue
+		}
+
+		rawTolerance := strings.TrimSpace(trimmed[i+len(separator):])
+		tolerance, err := strconv.ParseFloat(rawTolerance, 64)
+		if err != nil || tolerance < 0 || math.IsNaN(tolerance) {
+			return "", 0, fmt.Errorf("%v is not a valid tolerance", rawTolerance)
+		}
+
+		return strings.TrimSpace(trimmed[:i]), tolerance, nil
+	}
+
+	approximate := strings.HasPrefix(trimmed, "~")
+	if approximate {
+		trimmed = strings.TrimSpace(trimmed[1:])
+	}
+
+	if approximate || c.InferPrecision {
+		if tolerance, ok := writtenPrecision(trimmed); ok {
+			return trimmed, tolerance, nil
+		}
+	}
+
+	return trimmed, c.Epsilon, nil
+}
+
+func CompareBool(raw string, actual interface{}) error {
+	return DefaultBoolVocabulary.Compare(raw, actual)
+}
+
+// Compare compares a raw value to an actual bool using the words in the vocabulary.
+func (v BoolVocabulary) Compare(raw string, actual interface{}) error {
+	ab, ok := actual.(bool)
+	if !ok {
+		return fmt.Errorf("%v is not a bool", actual)
+	}
+
+	eb, err := v.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if eb != ab {
+		return fmt.Errorf("expected %v, but got %v", eb, ab)
+	}
+
+	return nil
+}
+
+func CompareDuration(raw string, actual interface{}) error {
+	ad, ok := actual.(time.Duration)
+	if !ok {
+		return fmt.Errorf("%v is not time.Duration", actual)
+	}
+
+	ed, unit, err := parseDuration(raw)
+	if err != nil {
+		return err
+	}
+
+	if ed != ad {
+		return fmt.Errorf("expected %v, but got %v", formatDuration(ed, unit), formatDuration(ad, unit))
+	}
+
+	return nil
+}
+
+// CompareTextMarshaler compares a raw value to the text of an actual encoding.TextMarshaler.
+// If the actual type also implements encoding.TextUnmarshaler, the raw value is normalized
+// by unmarshaling and marshaling it again before being compared.
+func CompareTextMarshaler(raw string, actual interface{}) error {
+	m, ok := actual.(encoding.TextMarshaler)
+	if !ok {
+		return fmt.Errorf("%v does not implement encoding.TextMarshaler", actual)
+	}
+
+	actualText, err := m.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	expectedText := []byte(raw)
+	tp := reflect.TypeOf(actual)
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+
+	ptr := reflect.New(tp)
+	if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(raw)); err != nil {
+			return err
+		}
+
+		if expectedText, err = ptr.Interface().(encoding.TextMarshaler).MarshalText(); err != nil {
+			return err
+		}
+	}
+
+	if string(expectedText) != string(actualText) {
+		return fmt.Errorf("expected %s, but got %s", expectedText, actualText)
+	}
+
+	return nil
+}
+
+// CompareStringer compares a raw value to the result of calling String on an actual fmt.Stringer.
+func CompareStringer(raw string, actual interface{}) error {
+	s, ok := actual.(fmt.Stringer)
+	if !ok {
+		return fmt.Errorf("%v does not implement fmt.Stringer", actual)
+	}
+
+	if as := s.String(); as != raw {
+		return fmt.Errorf("expected %v, but got %v", raw, as)
+	}
+
+	return nil
+}
+
+// CompareJSON compares a raw JSON value to the JSON encoding of an actual value.
+// The comparison is semantic, so the order of object keys and whitespace are ignored,
+// and differences are reported with the path where they were found.
+// Raw values that are not valid JSON are compared as JSON strings.
+func CompareJSON(raw string, actual interface{}) error {
+	data := []byte(raw)
+	if !json.Valid(data) {
+		data, _ = json.Marshal(raw)
+	}
+
+	expected, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+
+	actualData, err := json.Marshal(actual)
+	if err != nil {
+		return err
+	}
+
+	actualJSON, err := decodeJSON(actualData)
+	if err != nil {
+		return err
+	}
+
+	diffs := diffJSON("$", expected, actualJSON)
+	if len(diffs) > 0 {
+		return fmt.Errorf("%v", strings.Join(diffs, "; "))
+	}
+
+	return nil
+}
+
+func CompareBigInt(raw string, actual interface{}) error {
+	ai, ok := actual.(*big.Int)
+	if !ok {
+		return fmt.Errorf("%v is not *big.Int", actual)
+	}
+
+	ei, err := ParseBigInt(raw)
+	if err != nil {
+		return err
+	}
+
+	if ei.(*big.Int).Cmp(ai) != 0 {
+		return fmt.Errorf("expected %v, but got %v", ei, ai)
+	}
+
+	return nil
+}
+
+func CompareBigFloat(raw string, actual interface{}) error {
+	return DecimalComparer{}.CompareBigFloat(raw, actual)
+}
+
+func CompareBigRat(raw string, actual interface{}) error {
+	return DecimalComparer{}.CompareBigRat(raw, actual)
+}
+
+// CompareBigFloat compares a raw decimal value to an actual *big.Float following the comparer's rule.
+func (c DecimalComparer) CompareBigFloat(raw string, actual interface{}) error {
+	af, ok := actual.(*big.Float)
+	if !ok {
+		return fmt.Errorf("%v is not *big.Float", actual)
+	}
+
+	if af.IsInf() {
+		return fmt.Errorf("expected %v, but got %v", strings.TrimSpace(raw), af)
+	}
+
+	ar, _ := af.Rat(nil)
+	return c.compareDecimal(raw, ar)
+}
+
+// CompareBigRat compares a raw decimal value to an actual *big.Rat following the comparer's rule.
+func (c DecimalComparer) CompareBigRat(raw string, actual interface{}) error {
+	ar, ok := actual.(*big.Rat)
+	if !ok {
+		return fmt.Errorf("%v is not *big.Rat", actual)
+	}
+
+	return c.compareDecimal(raw, ar)
+}
+
+func (c DecimalComparer) compareDecimal(raw string, actual *big.Rat) error {
+	expected, err := ParseBigRat(raw)
+	if err != nil {
+		return err
+	}
+
+	er := expected.(*big.Rat)
+	written := decimalPlaces(raw)
+	matches := er.Cmp(actual) == 0
+	if c.Rule == DecimalRounded && written >= 0 {
+		matches = er.Cmp(roundRat(actual, written)) == 0
+	}
+
+	if matches {
+		return nil
+	}
+
+	places := written
+	if c.Rule != DecimalRounded || written < 0 {
+		places = exactDecimalPlaces(actual, written)
+	}
+
+	return fmt.Errorf("expected %v, but got %v", strings.TrimSpace(raw), actual.FloatString(places))
+}
+
+// CompareBytes compares a raw value to an actual []byte. Differences are reported with the offset
+// of the first differing byte, and both values are formatted in the encoding used in the raw value.
+func CompareBytes(raw string, actual interface{}) error {
+	ab, ok := actual.([]byte)
+	if !ok {
+		return fmt.Errorf("%v is not []byte", actual)
+	}
+
+	eb, prefix, err := parseBytes(raw)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(eb, ab) {
+		return nil
+	}
+
+	offset := 0
+	for offset < len(eb) && offset < len(ab) && eb[offset] == ab[offset] {
+		offset++
+	}
+
+	return fmt.Errorf("expected %v, but got %v (first difference at byte %v, expected %v bytes, got %v)",
+		formatBytes(eb, prefix), formatBytes(ab, prefix), offset, len(eb), len(ab))
+}
+
+// CompareIP compares a raw value to an actual net.IP. IPv4 addresses match their IPv4-mapped IPv6 form.
+func CompareIP(raw string, actual interface{}) error {
+	ai, ok := actual.(net.IP)
+	if !ok {
+		return fmt.Errorf("%v is not net.IP", actual)
+	}
+
+	ei, err := ParseIP(raw)
+	if err != nil {
+		return err
+	}
+
+	if !ei.(net.IP).Equal(ai) {
+		return fmt.Errorf("expected %v, but got %v", ei, ai)
+	}
+
+	return nil
+}
+
+// CompareIPNet compares a raw value in CIDR notation to an actual *net.IPNet.
+func CompareIPNet(raw string, actual interface{}) error {
+	an, ok := actual.(*net.IPNet)
+	if !ok {
+		return fmt.Errorf("%v is not *net.IPNet", actual)
+	}
+
+	parsed, err := ParseIPNet(raw)
+	if err != nil {
+		return err
+	}
+
+	en := parsed.(*net.IPNet)
+	if !en.IP.Equal(an.IP) || prefixLength(en) != prefixLength(an) {
+		return fmt.Errorf("expected %v, but got %v", en, an)
+	}
+
+	return nil
+}
+
+func CompareHardwareAddr(raw string, actual interface{}) error {
+	ah, ok := actual.(net.HardwareAddr)
+	if !ok {
+		return fmt.Errorf("%v is not net.HardwareAddr", actual)
+	}
+
+	eh, err := net.ParseMAC(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(eh, ah) {
+		return fmt.Errorf("expected %v, but got %v", eh, ah)
+	}
+
+	return nil
+}
+
+// CompareURL compares a raw value to an actual *url.URL. The scheme and host are compared
+// case-insensitively, and the order of query parameters is ignored.
+func CompareURL(raw string, actual interface{}) error {
+	au, ok := actual.(*url.URL)
+	if !ok {
+		return fmt.Errorf("%v is not *url.URL", actual)
+	}
+
+	eu, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+
+	if normalizeURL(eu) != normalizeURL(au) {
+		return fmt.Errorf("expected %v, but got %v", eu, au)
+	}
+
+	return nil
+}
+
+// CompareMailAddress compares a raw value to an actual *mail.Address.
+// The domain of the address is compared case-insensitively.
+func CompareMailAddress(raw string, actual interface{}) error {
+	aa, ok := actual.(*mail.Address)
+	if !ok {
+		return fmt.Errorf("%v is not *mail.Address", actual)
+	}
+
+	ea, err := mail.ParseAddress(raw)
+	if err != nil {
+		return err
+	}
+
+	if ea.Name != aa.Name || normalizeEmail(ea.Address) != normalizeEmail(aa.Address) {
+		return fmt.Errorf("expected %v, but got %v", ea, aa)
+	}
+
+	return nil
+}
+
 func CompareTime(raw string, actual interface{}) error {
+	return TimeParser{}.Compare(raw, actual)
+}
+
+// Compare compares a raw value to an actual time.Time, resolving relative expressions against the parser's clock.
+// Times are compared as instants, so the same instant in different locations is a match.
+func (p TimeParser) Compare(raw string, actual interface{}) error {
 	at, ok := actual.(time.Time)
 	if !ok {
 		return fmt.Errorf("%v is not time.Time", actual)
 	}
 
-	et, err := ParseTime(raw)
+	expected, tolerance := raw, p.Tolerance
+	if i := strings.LastIndex(strings.ToLower(raw), " within "); i >= 0 {
+		d, _, err := parseDuration(raw[i+len(" within "):])
+		if err != nil {
+			return err
+		}
+
+		expected, tolerance = raw[:i], d
+	}
+
+	et, err := p.Parse(expected)
+	if err != nil {
+		return err
+	}
+
+	if tolerance == 0 {
+		if !et.(time.Time).Equal(at) {
+			return fmt.Errorf("expected %v, but got %v", et, at)
+		}
+
+		return nil
+	}
+
+	diff := at.Sub(et.(time.Time))
+	if diff < 0 {
+		diff = -diff
+	}
+
+	if diff > tolerance {
+		return fmt.Errorf("expected %v within %v, but got %v, which is %v off", et, tolerance, at, diff)
+	}
+
+	return nil
+}
+
+func compareSigned(raw string, actual int64, bitSize int, typeName string) error {
+	expected, err := parseSigned(raw, bitSize, typeName)
+	if err != nil {
+		return err
+	}
+
+	if expected != actual {
+		return fmt.Errorf("expected %v, but got %v", expected, actual)
+	}
+
+	return nil
+}
+
+func compareUnsigned(raw string, actual uint64, bitSize int, typeName string) error {
+	expected, err := parseUnsigned(raw, bitSize, typeName)
 	if err != nil {
 		return err
 	}
 
-	if et != at {
-		return fmt.Errorf("expected %v, but got %v", et, at)
+	if expected != actual {
+		return fmt.Errorf("expected %v, but got %v", expected, actual)
 	}
 
 	return nil
 }
+
+func formatFloat(f float64, bitSize int) string {
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+func formatDuration(d time.Duration, unit *durationUnit) string {
+	if unit == nil {
+		return d.String()
+	}
+
+	quantity := float64(d) / float64(unit.size)
+	if quantity == 1 {
+		return "1 " + unit.name
+	}
+
+	return strconv.FormatFloat(quantity, 'f', -1, 64) + " " + unit.name + "s"
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func diffJSON(path string, expected, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: expected %v, but got %v", path, formatJSON(expected), formatJSON(actual))}
+		}
+
+		keys := []string{}
+		for key := range e {
+			keys = append(keys, key)
+		}
+
+		for key := range a {
+			if _, ok := e[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+		diffs := []string{}
+		for _, key := range keys {
+			keyPath := path + "." + key
+			ev, inExpected := e[key]
+			av, inActual := a[key]
+			switch {
+			case !inActual:
+				diffs = append(diffs, fmt.Sprintf("%v: missing, expected %v", keyPath, formatJSON(ev)))
+			case !inExpected:
+				diffs = append(diffs, fmt.Sprintf("%v: unexpected %v", keyPath, formatJSON(av)))
+			default:
+				diffs = append(diffs, diffJSON(keyPath, ev, av)...)
+			}
+		}
+
+		return diffs
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: expected %v, but got %v", path, formatJSON(expected), formatJSON(actual))}
+		}
+
+		if len(e) != len(a) {
+			return []string{fmt.Sprintf("%v: expected %v elements, but got %v", path, len(e), len(a))}
+		}
+
+		diffs := []string{}
+		for i := range e {
+			diffs = append(diffs, diffJSON(fmt.Sprintf("%v[%v]", path, i), e[i], a[i])...)
+		}
+
+		return diffs
+	case json.Number:
+		if a, ok := actual.(json.Number); ok && equalNumbers(e, a) {
+			return nil
+		}
+	default:
+		if reflect.DeepEqual(expected, actual) {
+			return nil
+		}
+	}
+
+	return []string{fmt.Sprintf("%v: expected %v, but got %v", path, formatJSON(expected), formatJSON(actual))}
+}
+
+func equalNumbers(a, b json.Number) bool {
+	ar, aok := new(big.Rat).SetString(a.String())
+	br, bok := new(big.Rat).SetString(b.String())
+	return aok && bok && ar.Cmp(br) == 0
+}
+
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// decimalPlaces returns the number of decimals written in a raw value,
+// or -1 if it is written as a fraction or in exponent notation.
+func decimalPlaces(raw string) int {
+	trimmed := strings.TrimSpace(raw)
+	if strings.ContainsAny(trimmed, "/eE") {
+		return -1
+	}
+
+	dot := strings.Index(trimmed, ".")
+	if dot < 0 {
+		return 0
+	}
+
+	return len(trimmed) - dot - 1
+}
+
+// cellStringMode splits a cell that starts with string modes in parentheses, such as "(fold) Hello",
+// into the modes and the rest of the cell.
+func cellStringMode(raw string) (StringMode, string, bool) {
+	end := strings.Index(raw, ")")
+	if !strings.HasPrefix(raw, "(") || end < 0 {
+		return 0, "", false
+	}
+
+	mode, err := ParseStringMode(raw[1:end])
+	if err != nil || strings.TrimSpace(raw[1:end]) == "" {
+		return 0, "", false
+	}
+
+	return mode, strings.TrimPrefix(raw[end+1:], " "), true
+}
+
+var invisibleNames = map[rune]string{
+	' ':      "SPACE",
+	'\t':     "TAB",
+	'\n':     "LINE FEED",
+	'\r':     "CARRIAGE RETURN",
+	'\u00a0': "NO-BREAK SPACE",
+	'\u2007': "FIGURE SPACE",
+	'\u2009': "THIN SPACE",
+	'\u200b': "ZERO WIDTH SPACE",
+	'\u200c': "ZERO WIDTH NON-JOINER",
+	'\u200d': "ZERO WIDTH JOINER",
+	'\u202f': "NARROW NO-BREAK SPACE",
+	'\ufeff': "ZERO WIDTH NO-BREAK SPACE",
+}
+
+// invisibleDifference describes the first character that differs between two strings
+// if either side of it can't be told apart by looking at the strings.
+func invisibleDifference(expected, actual string) (string, bool) {
+	er, ar := []rune(expected), []rune(actual)
+	i := 0
+	for i < len(er) && i < len(ar) && er[i] == ar[i] {
+		i++
+	}
+
+	if !invisibleAt(er, i) && !invisibleAt(ar, i) {
+		return "", false
+	}
+
+	return fmt.Sprintf("character %v: expected %v, but got %v", i+1, describeAt(er, i), describeAt(ar, i)), true
+}
+
+// invisibleAt reports whether the character at an index is invisible or followed by combining marks,
+// which make it look like a different character.
+func invisibleAt(runes []rune, i int) bool {
+	return i < len(runes) && isInvisible(runes[i]) || i+1 < len(runes) && unicode.Is(unicode.Mn, runes[i+1])
+}
+
+func isInvisible(r rune) bool {
+	return unicode.IsSpace(r) || !unicode.IsGraphic(r) || unicode.In(r, unicode.Mn, unicode.Cf, unicode.Zs)
+}
+
+// describeAt describes the character at an index along with the combining marks that follow it.
+func describeAt(runes []rune, i int) string {
+	if i >= len(runes) {
+		return "end of text"
+	}
+
+	description := describeRune(runes[i])
+	for j := i + 1; j < len(runes) && unicode.Is(unicode.Mn, runes[j]); j++ {
+		description += " + " + describeRune(runes[j])
+	}
+
+	return description
+}
+
+func describeRune(r rune) string {
+	switch {
+	case invisibleNames[r] != "":
+		return fmt.Sprintf("%U %v", r, invisibleNames[r])
+	case isInvisible(r):
+		return fmt.Sprintf("%U", r)
+	default:
+		return strconv.QuoteRune(r)
+	}
+}
+
+// quoteVisible quotes a string, escaping invisible characters other than plain spaces.
+func quoteVisible(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r != ' ' && isInvisible(r):
+			quoted := strconv.QuoteRuneToASCII(r)
+			b.WriteString(quoted[1 : len(quoted)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writtenPrecision returns half a unit of the last digit written in a decimal number,
+// so 3.14 gives 0.005 and 1.5e3 gives 50.
+func writtenPrecision(raw string) (float64, bool) {
+	mantissa, exponent := raw, 0
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		e, err := strconv.Atoi(raw[i+1:])
+		if err != nil {
+			return 0, false
+		}
+
+		mantissa, exponent = raw[:i], e
+	}
+
+	places := decimalPlaces(mantissa)
+	if places < 0 {
+		return 0, false
+	}
+
+	return 0.5 * math.Pow10(exponent-places), true
+}
+
+// exactDecimalPlaces returns the number of decimals needed to represent a value exactly,
+// with at least min decimals and at most 20.
+func exactDecimalPlaces(r *big.Rat, min int) int {
+	if min < 0 {
+		min = 0
+	}
+
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	places := 0
+	for !scaled.IsInt() && places < 20 {
+		scaled.Mul(scaled, ten)
+		places++
+	}
+
+	if places < min {
+		return min
+	}
+
+	return places
+}
+
+// roundRat rounds a value half away from zero to a number of decimals.
+func roundRat(r *big.Rat, places int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// Adding or subtracting one half before truncating rounds half away from zero.
+	half := big.NewRat(1, 2)
+	if scaled.Sign() < 0 {
+		scaled.Sub(scaled, half)
+	} else {
+		scaled.Add(scaled, half)
+	}
+
+	truncated := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	return new(big.Rat).SetFrac(truncated, scale)
+}
+
+func formatBytes(b []byte, prefix string) string {
+	switch prefix {
+	case hexPrefix:
+		return hexPrefix + hex.EncodeToString(b)
+	case base64Prefix:
+		return base64Prefix + base64.StdEncoding.EncodeToString(b)
+	}
+
+	if !utf8.Valid(b) || strings.IndexFunc(string(b), func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return hexPrefix + hex.EncodeToString(b)
+	}
+
+	return string(b)
+}
+
+// prefixLength returns the length of the network prefix, counting IPv4 networks
+// with 16-byte masks as if their masks were 4 bytes long.
+func prefixLength(n *net.IPNet) int {
+	ones, bits := n.Mask.Size()
+	if bits == 8*net.IPv6len && n.IP.To4() != nil {
+		return ones - 8*(net.IPv6len-net.IPv4len)
+	}
+
+	return ones
+}
+
+func normalizeURL(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(u.Scheme)
+	normalized.Host = strings.ToLower(u.Host)
+	normalized.RawQuery = u.Query().Encode()
+	return normalized.String()
+}
+
+func normalizeEmail(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+
+	return address[:at] + strings.ToLower(address[at:])
+}
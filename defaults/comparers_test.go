@@ -1,6 +1,11 @@
 package defaults
 
 import (
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
 	"testing"
 	"time"
 
@@ -47,6 +52,391 @@ func TestCompareInt(t *testing.T) {
 	})
 }
 
+func TestCompareSizedNumbers(t *testing.T) {
+	t.Run("returns nil for equal values", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			compare func(string, interface{}) error
+			raw     string
+			actual  interface{}
+		}{
+			{name: "int8", compare: CompareInt8, raw: "-8", actual: int8(-8)},
+			{name: "int16", compare: CompareInt16, raw: "16", actual: int16(16)},
+			{name: "int32", compare: CompareInt32, raw: "32", actual: int32(32)},
+			{name: "int64", compare: CompareInt64, raw: "64", actual: int64(64)},
+			{name: "uint", compare: CompareUint, raw: "1", actual: uint(1)},
+			{name: "uint8", compare: CompareUint8, raw: "8", actual: uint8(8)},
+			{name: "uint16", compare: CompareUint16, raw: "16", actual: uint16(16)},
+			{name: "uint32", compare: CompareUint32, raw: "32", actual: uint32(32)},
+			{name: "uint64", compare: CompareUint64, raw: "64", actual: uint64(64)},
+			{name: "float32", compare: CompareFloat32, raw: "0.1", actual: float32(0.1)},
+			{name: "float64", compare: CompareFloat64, raw: "0.1", actual: 0.1},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.compare(tc.raw, tc.actual)
+
+				require.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("returns error for actual of a different type", func(t *testing.T) {
+		err := CompareInt64("123", 123)
+
+		require.EqualError(t, err, "123 is not an int64")
+	})
+
+	t.Run("returns error for different values", func(t *testing.T) {
+		err := CompareUint16("12", uint16(13))
+
+		require.EqualError(t, err, "expected 12, but got 13")
+	})
+
+	t.Run("returns error for different floats", func(t *testing.T) {
+		err := CompareFloat32("0.1", float32(0.2))
+
+		require.EqualError(t, err, "expected 0.1, but got 0.2")
+	})
+
+	t.Run("returns error for expected value out of range", func(t *testing.T) {
+		err := CompareInt8("300", int8(44))
+
+		require.EqualError(t, err, "300 is out of range for int8 (-128 to 127)")
+	})
+}
+
+func TestCompareBool(t *testing.T) {
+	t.Run("returns nil for equal booleans", func(t *testing.T) {
+		err := CompareBool("yes", true)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error for actual that isn't a bool", func(t *testing.T) {
+		err := CompareBool("yes", "yes")
+
+		require.EqualError(t, err, "yes is not a bool")
+	})
+
+	t.Run("returns error for different booleans", func(t *testing.T) {
+		err := CompareBool("off", true)
+
+		require.EqualError(t, err, "expected false, but got true")
+	})
+}
+
+func TestCompareDuration(t *testing.T) {
+	t.Run("returns nil for equal durations", func(t *testing.T) {
+		err := CompareDuration("90 seconds", 90*time.Second)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error for actual that isn't a duration", func(t *testing.T) {
+		err := CompareDuration("90 seconds", 90)
+
+		require.EqualError(t, err, "90 is not time.Duration")
+	})
+
+	t.Run("reports difference in the unit of the expected value", func(t *testing.T) {
+		err := CompareDuration("90 seconds", 2*time.Minute)
+
+		require.EqualError(t, err, "expected 90 seconds, but got 120 seconds")
+	})
+
+	t.Run("reports difference in fractional units", func(t *testing.T) {
+		err := CompareDuration("1 day", 36*time.Hour)
+
+		require.EqualError(t, err, "expected 1 day, but got 1.5 days")
+	})
+
+	t.Run("reports difference in Go syntax", func(t *testing.T) {
+		err := CompareDuration("1h30m", time.Hour)
+
+		require.EqualError(t, err, "expected 1h30m0s, but got 1h0m0s")
+	})
+}
+
+func TestCompareTextMarshaler(t *testing.T) {
+	t.Run("returns nil for equal normalized text", func(t *testing.T) {
+		err := CompareTextMarshaler("0:0:0:0:0:0:0:1", net.ParseIP("::1"))
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error for different text", func(t *testing.T) {
+		err := CompareTextMarshaler("10.0.0.1", net.ParseIP("10.0.0.2"))
+
+		require.EqualError(t, err, "expected 10.0.0.1, but got 10.0.0.2")
+	})
+
+	t.Run("returns error for invalid expected value", func(t *testing.T) {
+		err := CompareTextMarshaler("nope", net.ParseIP("10.0.0.2"))
+
+		require.EqualError(t, err, "invalid IP address: nope")
+	})
+
+	t.Run("returns error for actual that is not a marshaler", func(t *testing.T) {
+		err := CompareTextMarshaler("1", 1)
+
+		require.EqualError(t, err, "1 does not implement encoding.TextMarshaler")
+	})
+}
+
+func TestCompareStringer(t *testing.T) {
+	t.Run("returns nil for equal strings", func(t *testing.T) {
+		err := CompareStringer("March", time.March)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error for different strings", func(t *testing.T) {
+		err := CompareStringer("March", time.May)
+
+		require.EqualError(t, err, "expected March, but got May")
+	})
+
+	t.Run("returns error for actual that is not a stringer", func(t *testing.T) {
+		err := CompareStringer("1", 1)
+
+		require.EqualError(t, err, "1 does not implement fmt.Stringer")
+	})
+}
+
+func TestCompareJSON(t *testing.T) {
+	actual := map[string]interface{}{
+		"name":    "John",
+		"age":     42,
+		"address": map[string]interface{}{"city": "Berlin"},
+		"tags":    []string{"a", "b"},
+	}
+
+	t.Run("returns nil for semantically equal JSON", func(t *testing.T) {
+		err := CompareJSON(`{"tags": ["a", "b"], "address": {"city": "Berlin"}, "age": 42.0, "name": "John"}`, actual)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error with path level differences", func(t *testing.T) {
+		err := CompareJSON(`{"name": "Mary", "address": {"city": "Paris", "zip": "75001"}, "tags": ["a"]}`, actual)
+
+		require.EqualError(t, err, `$.address.city: expected "Paris", but got "Berlin"; $.address.zip: missing, expected "75001"; $.age: unexpected 42; $.name: expected "Mary", but got "John"; $.tags: expected 1 elements, but got 2`)
+	})
+
+	t.Run("returns error for different types", func(t *testing.T) {
+		err := CompareJSON(`[1, 2]`, map[string]int{"a": 1})
+
+		require.EqualError(t, err, `$: expected [1,2], but got {"a":1}`)
+	})
+
+	t.Run("returns error for invalid actual", func(t *testing.T) {
+		err := CompareJSON(`{}`, func() {})
+
+		require.EqualError(t, err, "json: unsupported type: func()")
+	})
+}
+
+func TestCompareBigNumbers(t *testing.T) {
+	t.Run("compares big.Int", func(t *testing.T) {
+		require.NoError(t, CompareBigInt("12345678901234567890", new(big.Int).SetUint64(12345678901234567890)))
+		require.EqualError(t, CompareBigInt("1", big.NewInt(2)), "expected 1, but got 2")
+		require.EqualError(t, CompareBigInt("1", 1), "1 is not *big.Int")
+	})
+
+	t.Run("compares decimal values", func(t *testing.T) {
+		require.NoError(t, CompareBigRat("12.50", big.NewRat(25, 2)))
+		require.NoError(t, CompareBigFloat("12.50", big.NewFloat(12.5)))
+		require.NoError(t, CompareBigRat("1/3", big.NewRat(1, 3)))
+	})
+
+	t.Run("reports differences with the decimals written", func(t *testing.T) {
+		err := CompareBigRat("12.50", big.NewRat(12499, 1000))
+
+		require.EqualError(t, err, "expected 12.50, but got 12.499")
+	})
+
+	t.Run("reports differences with at least the decimals written", func(t *testing.T) {
+		err := CompareBigRat("12.50", big.NewRat(13, 1))
+
+		require.EqualError(t, err, "expected 12.50, but got 13.00")
+	})
+
+	t.Run("compares rounded values", func(t *testing.T) {
+		c := DecimalComparer{Rule: DecimalRounded}
+
+		require.NoError(t, c.CompareBigRat("12.50", big.NewRat(12499, 1000)))
+		require.NoError(t, c.CompareBigRat("-12.5", big.NewRat(-1245, 100)))
+		require.EqualError(t, c.CompareBigFloat("12.50", big.NewFloat(12.494)), "expected 12.50, but got 12.49")
+	})
+
+	t.Run("returns error for infinite float", func(t *testing.T) {
+		err := CompareBigFloat("1", new(big.Float).SetInf(false))
+
+		require.EqualError(t, err, "expected 1, but got +Inf")
+	})
+}
+
+func TestCompareStringModes(t *testing.T) {
+	t.Run("compares with the comparer's mode", func(t *testing.T) {
+		require.NoError(t, StringComparer{Mode: StringFold}.Compare("straße", "STRASSE"))
+		require.NoError(t, StringComparer{Mode: StringTrim}.Compare("abc", " abc\t"))
+		require.NoError(t, StringComparer{Mode: StringCollapseSpace}.Compare("a b c", " a  b\n c "))
+		require.NoError(t, StringComparer{Mode: StringNFC}.Compare("caf\u00e9", "cafe\u0301"))
+		require.NoError(t, StringComparer{Mode: StringNFKC}.Compare("ffi", "\ufb03"))
+		require.NoError(t, StringComparer{Mode: StringPrefix | StringFold}.Compare("ord-", "ORD-1234"))
+		require.NoError(t, StringComparer{Mode: StringSuffix}.Compare("@example.com", "john@example.com"))
+	})
+
+	t.Run("compares with the cell's mode", func(t *testing.T) {
+		require.NoError(t, CompareString("(fold, trim) Hello", " HELLO "))
+		require.NoError(t, StringComparer{Mode: StringFold}.Compare("(prefix) Hel", "Hello"))
+		require.NoError(t, CompareString("(555) 123", "(555) 123"))
+	})
+
+	t.Run("returns error for different strings", func(t *testing.T) {
+		require.EqualError(t, StringComparer{Mode: StringFold}.Compare("abc", "abd"), "expected abc, but got abd")
+		require.EqualError(t, CompareString("(prefix) abc", "xabc"), "expected to start with abc, but got xabc")
+	})
+
+	t.Run("describes invisible differences", func(t *testing.T) {
+		require.EqualError(t, CompareString("abc", "abc "),
+			`expected "abc", but got "abc " (character 4: expected end of text, but got U+0020 SPACE)`)
+		require.EqualError(t, CompareString("a b", "a\u00a0b"),
+			`expected "a b", but got "a\u00a0b" (character 2: expected U+0020 SPACE, but got U+00A0 NO-BREAK SPACE)`)
+		require.EqualError(t, CompareString("caf\u00e9", "cafe\u0301"),
+			"expected \"caf\u00e9\", but got \"cafe\\u0301\" (character 4: expected 'é', but got 'e' + U+0301)")
+	})
+}
+
+func TestParseStringMode(t *testing.T) {
+	t.Run("parses mode names", func(t *testing.T) {
+		mode, err := ParseStringMode("fold|Trim, nfc")
+
+		require.NoError(t, err)
+		require.Equal(t, StringFold|StringTrim|StringNFC, mode)
+	})
+
+	t.Run("returns error for unknown names", func(t *testing.T) {
+		_, err := ParseStringMode("fold|loose")
+
+		require.EqualError(t, err, "unknown string mode loose, expected one of collapse, exact, fold, nfc, nfkc, prefix, suffix, trim")
+	})
+}
+
+func TestCompareFloatTolerance(t *testing.T) {
+	t.Run("compares within an explicit tolerance", func(t *testing.T) {
+		require.NoError(t, CompareFloat64("3.14 ± 0.01", 3.149))
+		require.NoError(t, CompareFloat64("3.14 +/- 0.01", 3.131))
+		require.EqualError(t, CompareFloat64("3.14 ± 0.01", 3.2), "expected 3.14 ± 0.01, but got 3.2, which is 0.06 off")
+	})
+
+	t.Run("infers the tolerance from the digits written", func(t *testing.T) {
+		require.NoError(t, CompareFloat64("~3.14", 3.1415926))
+		require.NoError(t, CompareFloat32("~2", float32(2.4)))
+		require.NoError(t, CompareFloat64("~1.5e3", 1540.0))
+		require.EqualError(t, CompareFloat64("~3.14", 3.146), "expected 3.14 ± 0.005, but got 3.146, which is 0.006 off")
+	})
+
+	t.Run("uses the comparer's epsilon", func(t *testing.T) {
+		c := FloatComparer{Epsilon: 0.001}
+		tenth := 0.1
+
+		require.NoError(t, c.CompareFloat64("0.3", tenth+0.2))
+		require.NoError(t, c.CompareFloat64("3.1 ± 0.1", 3.05))
+		require.Error(t, c.CompareFloat64("0.3", 0.302))
+	})
+
+	t.Run("infers precision for every cell", func(t *testing.T) {
+		c := FloatComparer{InferPrecision: true}
+
+		require.NoError(t, c.CompareFloat64("0.33", 1.0/3))
+		require.Error(t, c.CompareFloat64("0.333", 0.3))
+	})
+
+	t.Run("handles NaN and infinities", func(t *testing.T) {
+		require.NoError(t, CompareFloat64("NaN", math.NaN()))
+		require.NoError(t, CompareFloat64("-Inf", math.Inf(-1)))
+		require.NoError(t, CompareFloat32("+Inf ± 1", float32(math.Inf(1))))
+		require.EqualError(t, CompareFloat64("1 ± 10", math.NaN()), "expected 1, but got NaN")
+		require.EqualError(t, CompareFloat64("Inf", math.Inf(-1)), "expected +Inf, but got -Inf")
+		require.EqualError(t, CompareFloat64("NaN", 0.0), "expected NaN, but got 0")
+	})
+
+	t.Run("returns error for invalid tolerance", func(t *testing.T) {
+		require.EqualError(t, CompareFloat64("1 ± -1", 1.0), "-1 is not a valid tolerance")
+	})
+}
+
+func TestCompareBytes(t *testing.T) {
+	t.Run("returns nil for equal bytes", func(t *testing.T) {
+		require.NoError(t, CompareBytes("hex:0a0b", []byte{0x0a, 0x0b}))
+		require.NoError(t, CompareBytes("abc", []byte("abc")))
+	})
+
+	t.Run("returns error for actual that isn't bytes", func(t *testing.T) {
+		err := CompareBytes("abc", "abc")
+
+		require.EqualError(t, err, "abc is not []byte")
+	})
+
+	t.Run("reports first differing byte in the encoding used", func(t *testing.T) {
+		err := CompareBytes("hex:0a0b0c", []byte{0x0a, 0x0b, 0x0d})
+		require.EqualError(t, err, "expected hex:0a0b0c, but got hex:0a0b0d (first difference at byte 2, expected 3 bytes, got 3)")
+
+		err = CompareBytes("base64:CgsM", []byte{0x0a})
+		require.EqualError(t, err, "expected base64:CgsM, but got base64:Cg== (first difference at byte 1, expected 3 bytes, got 1)")
+
+		err = CompareBytes("abc", []byte("abd"))
+		require.EqualError(t, err, "expected abc, but got abd (first difference at byte 2, expected 3 bytes, got 3)")
+	})
+
+	t.Run("formats binary actual values as hex for plain text", func(t *testing.T) {
+		err := CompareBytes("abc", []byte{0x00, 0xff})
+
+		require.EqualError(t, err, "expected abc, but got hex:00ff (first difference at byte 0, expected 3 bytes, got 2)")
+	})
+}
+
+func TestCompareNetworkTypes(t *testing.T) {
+	t.Run("compares IPs with IPv4-mapped IPv6 equality", func(t *testing.T) {
+		require.NoError(t, CompareIP("::ffff:10.0.0.1", net.IPv4(10, 0, 0, 1).To4()))
+		require.EqualError(t, CompareIP("10.0.0.1", net.ParseIP("10.0.0.2")), "expected 10.0.0.1, but got 10.0.0.2")
+		require.EqualError(t, CompareIP("10.0.0.1", "10.0.0.1"), "10.0.0.1 is not net.IP")
+	})
+
+	t.Run("compares IPNets", func(t *testing.T) {
+		actual := &net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(104, 128)}
+
+		require.NoError(t, CompareIPNet("10.0.0.0/8", actual))
+		require.EqualError(t, CompareIPNet("10.0.0.0/16", actual), "expected 10.0.0.0/16, but got 10.0.0.0/8")
+	})
+
+	t.Run("compares hardware addresses", func(t *testing.T) {
+		actual, _ := net.ParseMAC("00:1a:2b:3c:4d:5e")
+
+		require.NoError(t, CompareHardwareAddr("00-1A-2B-3C-4D-5E", actual))
+		require.EqualError(t, CompareHardwareAddr("00:1a:2b:3c:4d:5f", actual), "expected 00:1a:2b:3c:4d:5f, but got 00:1a:2b:3c:4d:5e")
+	})
+
+	t.Run("compares URLs ignoring query order and host case", func(t *testing.T) {
+		actual, _ := url.Parse("https://example.com/search?q=go&page=2")
+
+		require.NoError(t, CompareURL("HTTPS://Example.com/search?page=2&q=go", actual))
+		require.EqualError(t, CompareURL("https://example.com/search?q=go", actual),
+			"expected https://example.com/search?q=go, but got https://example.com/search?q=go&page=2")
+	})
+
+	t.Run("compares mail addresses ignoring domain case", func(t *testing.T) {
+		actual := &mail.Address{Name: "John", Address: "john@example.com"}
+
+		require.NoError(t, CompareMailAddress("John <john@EXAMPLE.com>", actual))
+		require.EqualError(t, CompareMailAddress("John <JOHN@example.com>", actual),
+			`expected "John" <JOHN@example.com>, but got "John" <john@example.com>`)
+	})
+}
+
 func TestCompareTime(t *testing.T) {
 	validTime, err := time.Parse(time.RFC3339, "2020-11-05T16:01:54Z")
 	require.NoError(t, err)
@@ -71,4 +461,41 @@ func TestCompareTime(t *testing.T) {
 
 		require.EqualError(t, err, "expected 2020-11-05 16:01:54 +0000 UTC, but got 2020-11-05 17:01:54 +0000 UTC")
 	})
+
+	t.Run("returns nil for the same instant in different locations", func(t *testing.T) {
+		err := CompareTime("2020-11-05T17:01:54+01:00", validTime.In(time.Local))
+
+		require.NoError(t, err)
+	})
+
+	t.Run("ignores monotonic clock readings", func(t *testing.T) {
+		now := time.Now()
+		parser := TimeParser{Now: func() time.Time { return now.Round(0) }}
+
+		err := parser.Compare("now", now)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("compares within tolerance", func(t *testing.T) {
+		parser := TimeParser{Tolerance: 2 * time.Second}
+
+		require.NoError(t, parser.Compare("2020-11-05T16:01:53Z", validTime))
+		require.EqualError(t, parser.Compare("2020-11-05T16:01:50Z", validTime),
+			"expected 2020-11-05 16:01:50 +0000 UTC within 2s, but got 2020-11-05 16:01:54 +0000 UTC, which is 4s off")
+	})
+
+	t.Run("compares within tolerance given in the value", func(t *testing.T) {
+		require.NoError(t, CompareTime("2020-11-05T16:01:50Z within 5 seconds", validTime))
+		require.EqualError(t, CompareTime("2020-11-05T16:01:50Z within 1s", validTime),
+			"expected 2020-11-05 16:01:50 +0000 UTC within 1s, but got 2020-11-05 16:01:54 +0000 UTC, which is 4s off")
+	})
+
+	t.Run("resolves relative expressions against the clock", func(t *testing.T) {
+		parser := TimeParser{Now: func() time.Time { return validTime.Add(time.Hour) }}
+
+		err := parser.Compare("1 hour ago", validTime)
+
+		require.NoError(t, err)
+	})
 }
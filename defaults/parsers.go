@@ -1,15 +1,72 @@
 package defaults
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
-var supportedTimeLayouts = []string{
+// DefaultTimeLayouts are the layouts accepted by ParseTime and CompareTime,
+// and by any TimeParser that doesn't define its own.
+var DefaultTimeLayouts = []string{
 	time.RFC822,
 	time.RFC3339,
 	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+type durationUnit struct {
+	size time.Duration
+	name string
+}
+
+var (
+	nanosecondUnit  = durationUnit{size: time.Nanosecond, name: "nanosecond"}
+	microsecondUnit = durationUnit{size: time.Microsecond, name: "microsecond"}
+	millisecondUnit = durationUnit{size: time.Millisecond, name: "millisecond"}
+	secondUnit      = durationUnit{size: time.Second, name: "second"}
+	minuteUnit      = durationUnit{size: time.Minute, name: "minute"}
+	hourUnit        = durationUnit{size: time.Hour, name: "hour"}
+	dayUnit         = durationUnit{size: 24 * time.Hour, name: "day"}
+	weekUnit        = durationUnit{size: 7 * 24 * time.Hour, name: "week"}
+)
+
+var durationUnits = map[string]durationUnit{
+	"ns": nanosecondUnit, "nanosecond": nanosecondUnit, "nanoseconds": nanosecondUnit,
+	"us": microsecondUnit, "µs": microsecondUnit, "microsecond": microsecondUnit, "microseconds": microsecondUnit,
+	"ms": millisecondUnit, "millisecond": millisecondUnit, "milliseconds": millisecondUnit,
+	"s": secondUnit, "sec": secondUnit, "secs": secondUnit, "second": secondUnit, "seconds": secondUnit,
+	"m": minuteUnit, "min": minuteUnit, "mins": minuteUnit, "minute": minuteUnit, "minutes": minuteUnit,
+	"h": hourUnit, "hr": hourUnit, "hrs": hourUnit, "hour": hourUnit, "hours": hourUnit,
+	"d": dayUnit, "day": dayUnit, "days": dayUnit,
+	"w": weekUnit, "week": weekUnit, "weeks": weekUnit,
+}
+
+// BoolVocabulary lists the words that are accepted as true and false boolean values.
+// Words are matched case-insensitively, ignoring surrounding whitespace.
+type BoolVocabulary struct {
+	True  []string
+	False []string
+}
+
+// DefaultBoolVocabulary is the vocabulary used by ParseBool and CompareBool.
+var DefaultBoolVocabulary = BoolVocabulary{
+	True:  []string{"true", "yes", "on", "1", "✓"},
+	False: []string{"false", "no", "off", "0", "✗"},
 }
 
 func ParseString(raw string) (interface{}, error) {
@@ -17,14 +74,321 @@ func ParseString(raw string) (interface{}, error) {
 }
 
 func ParseInt(raw string) (interface{}, error) {
-	return strconv.Atoi(raw)
+	i, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, signedRangeError(err, raw, strconv.IntSize, "int")
+	}
+
+	return i, nil
+}
+
+func ParseInt8(raw string) (interface{}, error) {
+	i, err := parseSigned(raw, 8, "int8")
+	if err != nil {
+		return nil, err
+	}
+
+	return int8(i), nil
+}
+
+func ParseInt16(raw string) (interface{}, error) {
+	i, err := parseSigned(raw, 16, "int16")
+	if err != nil {
+		return nil, err
+	}
+
+	return int16(i), nil
+}
+
+func ParseInt32(raw string) (interface{}, error) {
+	i, err := parseSigned(raw, 32, "int32")
+	if err != nil {
+		return nil, err
+	}
+
+	return int32(i), nil
+}
+
+func ParseInt64(raw string) (interface{}, error) {
+	i, err := parseSigned(raw, 64, "int64")
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func ParseUint(raw string) (interface{}, error) {
+	u, err := parseUnsigned(raw, strconv.IntSize, "uint")
+	if err != nil {
+		return nil, err
+	}
+
+	return uint(u), nil
+}
+
+func ParseUint8(raw string) (interface{}, error) {
+	u, err := parseUnsigned(raw, 8, "uint8")
+	if err != nil {
+		return nil, err
+	}
+
+	return uint8(u), nil
+}
+
+func ParseUint16(raw string) (interface{}, error) {
+	u, err := parseUnsigned(raw, 16, "uint16")
+	if err != nil {
+		return nil, err
+	}
+
+	return uint16(u), nil
+}
+
+func ParseUint32(raw string) (interface{}, error) {
+	u, err := parseUnsigned(raw, 32, "uint32")
+	if err != nil {
+		return nil, err
+	}
+
+	return uint32(u), nil
+}
+
+func ParseUint64(raw string) (interface{}, error) {
+	u, err := parseUnsigned(raw, 64, "uint64")
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func ParseFloat32(raw string) (interface{}, error) {
+	f, err := parseFloat(raw, 32, "float32")
+	if err != nil {
+		return nil, err
+	}
+
+	return float32(f), nil
+}
+
+func ParseFloat64(raw string) (interface{}, error) {
+	f, err := parseFloat(raw, 64, "float64")
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func ParseBool(raw string) (interface{}, error) {
+	return DefaultBoolVocabulary.Parse(raw)
+}
+
+// Parse parses a raw value into a bool using the words in the vocabulary.
+func (v BoolVocabulary) Parse(raw string) (interface{}, error) {
+	word := strings.TrimSpace(raw)
+	for _, t := range v.True {
+		if strings.EqualFold(t, word) {
+			return true, nil
+		}
+	}
+
+	for _, f := range v.False {
+		if strings.EqualFold(f, word) {
+			return false, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unrecognized boolean %v, expected one of %v", raw, strings.Join(append(append([]string{}, v.True...), v.False...), ", "))
+}
+
+// Extend returns a new vocabulary with the given words added to the existing ones.
+func (v BoolVocabulary) Extend(truthy, falsy []string) BoolVocabulary {
+	return BoolVocabulary{
+		True:  append(append([]string{}, v.True...), truthy...),
+		False: append(append([]string{}, v.False...), falsy...),
+	}
+}
+
+// ParseDuration parses a duration written either in Go syntax, such as 1h30m, or as
+// spelled-out quantities, such as "90 seconds", "1.5 hours" or "2 days and 3 hours".
+func ParseDuration(raw string) (interface{}, error) {
+	d, _, err := parseDuration(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ParseTextUnmarshaler parses a raw value into a type that implements encoding.TextUnmarshaler,
+// either directly or through a pointer to it.
+func ParseTextUnmarshaler(tp reflect.Type, raw string) (interface{}, error) {
+	if tp.Kind() == reflect.Ptr {
+		ptr := reflect.New(tp.Elem())
+		if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(raw)); err != nil {
+				return nil, err
+			}
+
+			return ptr.Interface(), nil
+		}
+	}
+
+	ptr := reflect.New(tp)
+	u, ok := ptr.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%v does not implement encoding.TextUnmarshaler", tp)
+	}
+
+	if err := u.UnmarshalText([]byte(raw)); err != nil {
+		return nil, err
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+// ParseJSON decodes a raw JSON value into a given type. Raw values that are not valid JSON
+// are decoded as JSON strings, so that types implementing json.Unmarshaler for string values
+// can be written without quotes.
+func ParseJSON(tp reflect.Type, raw string) (interface{}, error) {
+	data := []byte(raw)
+	if !json.Valid(data) {
+		data, _ = json.Marshal(raw)
+	}
+
+	ptr := reflect.New(tp)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+func ParseBigInt(raw string) (interface{}, error) {
+	i, ok := new(big.Int).SetString(strings.TrimSpace(raw), 10)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a valid big.Int", raw)
+	}
+
+	return i, nil
+}
+
+// ParseBigFloat parses a raw value into a *big.Float with enough precision
+// to represent all the digits written in the table.
+func ParseBigFloat(raw string) (interface{}, error) {
+	trimmed := strings.TrimSpace(raw)
+	prec := uint(64 + 4*len(trimmed))
+	f, _, err := big.ParseFloat(trimmed, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not a valid big.Float", raw)
+	}
+
+	return f, nil
+}
+
+// ParseBigRat parses a raw value into a *big.Rat. Both decimals, such as 12.50,
+// and fractions, such as 1/3, are accepted.
+func ParseBigRat(raw string) (interface{}, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(raw))
+	if !ok {
+		return nil, fmt.Errorf("%v is not a valid big.Rat", raw)
+	}
+
+	return r, nil
+}
+
+// ParseBytes parses a raw value into a []byte. Values prefixed with hex: or base64: are decoded,
+// and any other value is taken as plain text. The text: prefix can be used for plain text that
+// would otherwise look like one of the encodings.
+func ParseBytes(raw string) (interface{}, error) {
+	b, _, err := parseBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func ParseIP(raw string) (interface{}, error) {
+	ip := net.ParseIP(strings.TrimSpace(raw))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %v", raw)
+	}
+
+	return ip, nil
+}
+
+// ParseIPNet parses a raw value in CIDR notation, such as 10.0.0.0/8, into a *net.IPNet.
+func ParseIPNet(raw string) (interface{}, error) {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	return ipNet, nil
+}
+
+func ParseHardwareAddr(raw string) (interface{}, error) {
+	return net.ParseMAC(strings.TrimSpace(raw))
+}
+
+func ParseURL(raw string) (interface{}, error) {
+	return url.Parse(strings.TrimSpace(raw))
+}
+
+func ParseMailAddress(raw string) (interface{}, error) {
+	return mail.ParseAddress(raw)
 }
 
 func ParseTime(raw string) (interface{}, error) {
+	return TimeParser{}.Parse(raw)
+}
+
+// TimeParser parses times written in one of the supported layouts, or as relative expressions
+// such as "now", "today", "yesterday 09:00", "in 3 days", "2 hours ago" or "start of month",
+// which are resolved against its clock.
+type TimeParser struct {
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	// Layouts are the accepted layouts, tried in order. If nil, DefaultTimeLayouts is used.
+	Layouts []string
+
+	// Location is used for values that don't specify a time zone, including relative
+	// expressions. If nil, UTC is used for layouts and the clock's location for expressions.
+	Location *time.Location
+
+	// Tolerance is the maximum difference between expected and actual times for them to match.
+	// It can also be given per value, as in "2020-11-05T16:01:54Z within 2s".
+	Tolerance time.Duration
+}
+
+// Parse parses a raw value into a time.Time.
+func (p TimeParser) Parse(raw string) (interface{}, error) {
+	if t, ok, err := p.parseRelative(raw); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		return t, nil
+	}
+
+	layouts := p.Layouts
+	if layouts == nil {
+		layouts = DefaultTimeLayouts
+	}
+
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
+
 	var fieldTime time.Time
-	var err error
-	for _, layout := range supportedTimeLayouts {
-		fieldTime, err = time.Parse(layout, raw)
+	err := fmt.Errorf("no layouts")
+	for _, layout := range layouts {
+		fieldTime, err = time.ParseInLocation(layout, raw, location)
 		if err != nil {
 			continue
 		}
@@ -38,3 +402,277 @@ func ParseTime(raw string) (interface{}, error) {
 
 	return fieldTime, nil
 }
+
+func (p TimeParser) now() time.Time {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	if p.Location != nil {
+		return now().In(p.Location)
+	}
+
+	return now()
+}
+
+// parseRelative resolves relative time expressions. It reports whether the raw value
+// looked like one, in which case the error describes why it could not be resolved.
+func (p TimeParser) parseRelative(raw string) (time.Time, bool, error) {
+	expr := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+	now := p.now()
+	switch {
+	case expr == "now":
+		return now, true, nil
+	case strings.HasPrefix(expr, "in "):
+		d, _, err := parseDuration(strings.TrimPrefix(expr, "in "))
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+		}
+
+		return now.Add(d), true, nil
+	case strings.HasSuffix(expr, " ago"):
+		d, _, err := parseDuration(strings.TrimSuffix(expr, " ago"))
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+		}
+
+		return now.Add(-d), true, nil
+	case strings.HasSuffix(expr, " from now"):
+		d, _, err := parseDuration(strings.TrimSuffix(expr, " from now"))
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+		}
+
+		return now.Add(d), true, nil
+	case strings.HasPrefix(expr, "start of "):
+		start, _, err := periodBounds(now, strings.TrimPrefix(expr, "start of "))
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+		}
+
+		return start, true, nil
+	case strings.HasPrefix(expr, "end of "):
+		_, end, err := periodBounds(now, strings.TrimPrefix(expr, "end of "))
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+		}
+
+		return end, true, nil
+	}
+
+	words := strings.SplitN(expr, " ", 2)
+	offsets := map[string]int{"yesterday": -1, "today": 0, "tomorrow": 1}
+	offset, ok := offsets[words[0]]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	day := startOfDay(now).AddDate(0, 0, offset)
+	if len(words) == 1 {
+		return day, true, nil
+	}
+
+	clock, err := parseClock(words[1])
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("unrecognized time expression %v: %v", raw, err)
+	}
+
+	return day.Add(clock), true, nil
+}
+
+// periodBounds returns the first and last instants of the day, week, month or year containing t,
+// or of the previous or next one when the period is preceded by "last" or "next".
+// Weeks start on Monday.
+func periodBounds(t time.Time, period string) (time.Time, time.Time, error) {
+	words := strings.Fields(period)
+	shift := 0
+	if len(words) == 2 {
+		switch words[0] {
+		case "last", "previous":
+			shift = -1
+		case "next":
+			shift = 1
+		case "this":
+		default:
+			return time.Time{}, time.Time{}, fmt.Errorf("unknown period %v", period)
+		}
+
+		words = words[1:]
+	}
+
+	if len(words) != 1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %v", period)
+	}
+
+	day := startOfDay(t)
+	var start, end time.Time
+	switch words[0] {
+	case "day":
+		start = day.AddDate(0, 0, shift)
+		end = start.AddDate(0, 0, 1)
+	case "week":
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		start = day.AddDate(0, 0, -daysSinceMonday+7*shift)
+		end = start.AddDate(0, 0, 7)
+	case "month":
+		start = time.Date(day.Year(), day.Month()+time.Month(shift), 1, 0, 0, 0, 0, day.Location())
+		end = start.AddDate(0, 1, 0)
+	case "year":
+		start = time.Date(day.Year()+shift, time.January, 1, 0, 0, 0, 0, day.Location())
+		end = start.AddDate(1, 0, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %v", period)
+	}
+
+	return start, end.Add(-time.Nanosecond), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseClock parses a time of day written as 15:04 or 15:04:05 into the duration since midnight.
+func parseClock(raw string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unrecognized time of day %v", raw)
+}
+
+func parseSigned(raw string, bitSize int, typeName string) (int64, error) {
+	i, err := strconv.ParseInt(raw, 10, bitSize)
+	if err != nil {
+		return 0, signedRangeError(err, raw, bitSize, typeName)
+	}
+
+	return i, nil
+}
+
+func parseUnsigned(raw string, bitSize int, typeName string) (uint64, error) {
+	u, err := strconv.ParseUint(raw, 10, bitSize)
+	if err == nil {
+		return u, nil
+	}
+
+	// strconv reports negative numbers as a syntax error for unsigned types,
+	// but for a table author they are just as out of range as a number that is too big.
+	if _, signedErr := strconv.ParseInt(raw, 10, 64); signedErr == nil && strings.HasPrefix(raw, "-") {
+		err = &strconv.NumError{Func: "ParseUint", Num: raw, Err: strconv.ErrRange}
+	}
+
+	if isRangeError(err) {
+		max := ^uint64(0) >> uint(64-bitSize)
+		return 0, fmt.Errorf("%v is out of range for %v (0 to %v)", raw, typeName, max)
+	}
+
+	return 0, err
+}
+
+func parseFloat(raw string, bitSize int, typeName string) (float64, error) {
+	f, err := strconv.ParseFloat(raw, bitSize)
+	if err != nil {
+		if isRangeError(err) {
+			return 0, fmt.Errorf("%v is out of range for %v", raw, typeName)
+		}
+
+		return 0, err
+	}
+
+	return f, nil
+}
+
+func signedRangeError(err error, raw string, bitSize int, typeName string) error {
+	if !isRangeError(err) {
+		return err
+	}
+
+	min := int64(-1) << uint(bitSize-1)
+	max := -(min + 1)
+	return fmt.Errorf("%v is out of range for %v (%v to %v)", raw, typeName, min, max)
+}
+
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+// parseDuration also returns the unit used when the duration was written as a single
+// spelled-out quantity, so that it can be formatted back in the same unit.
+func parseDuration(raw string) (time.Duration, *durationUnit, error) {
+	trimmed := strings.TrimSpace(raw)
+	if d, err := time.ParseDuration(trimmed); err == nil {
+		return d, nil, nil
+	}
+
+	words := []string{}
+	for _, word := range strings.Fields(strings.Replace(trimmed, ",", " ", -1)) {
+		if strings.ToLower(word) != "and" {
+			words = append(words, word)
+		}
+	}
+
+	if len(words) == 0 || len(words)%2 != 0 {
+		return 0, nil, fmt.Errorf("unrecognized duration %v", raw)
+	}
+
+	var total float64
+	var lastUnit durationUnit
+	for i := 0; i < len(words); i += 2 {
+		quantity, err := strconv.ParseFloat(words[i], 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("unrecognized duration %v", raw)
+		}
+
+		unit, ok := durationUnits[strings.ToLower(words[i+1])]
+		if !ok {
+			return 0, nil, fmt.Errorf("unrecognized duration unit %v in %v", words[i+1], raw)
+		}
+
+		total += quantity * float64(unit.size)
+		lastUnit = unit
+	}
+
+	if total > math.MaxInt64 || total < math.MinInt64 {
+		return 0, nil, fmt.Errorf("%v is out of range for time.Duration", raw)
+	}
+
+	if len(words) > 2 {
+		return time.Duration(math.Round(total)), nil, nil
+	}
+
+	return time.Duration(math.Round(total)), &lastUnit, nil
+}
+
+const (
+	hexPrefix    = "hex:"
+	base64Prefix = "base64:"
+	textPrefix   = "text:"
+)
+
+// parseBytes also returns the prefix of the encoding used, so that bytes can be formatted back in it.
+func parseBytes(raw string) ([]byte, string, error) {
+	switch {
+	case strings.HasPrefix(raw, hexPrefix):
+		b, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(raw, hexPrefix)))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid hex value %v: %v", raw, err)
+		}
+
+		return b, hexPrefix, nil
+	case strings.HasPrefix(raw, base64Prefix):
+		encoded := strings.TrimSpace(strings.TrimPrefix(raw, base64Prefix))
+		for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+			if b, err := encoding.DecodeString(encoded); err == nil {
+				return b, base64Prefix, nil
+			}
+		}
+
+		return nil, "", fmt.Errorf("invalid base64 value %v", raw)
+	default:
+		return []byte(strings.TrimPrefix(raw, textPrefix)), textPrefix, nil
+	}
+}
@@ -1,6 +1,11 @@
 package defaults
 
 import (
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
@@ -28,6 +33,357 @@ func TestParseInt(t *testing.T) {
 
 		require.EqualError(t, err, `strconv.Atoi: parsing "abc": invalid syntax`)
 	})
+
+	t.Run("returns error for integer out of range", func(t *testing.T) {
+		_, err := ParseInt("99999999999999999999")
+
+		require.EqualError(t, err, "99999999999999999999 is out of range for int (-9223372036854775808 to 9223372036854775807)")
+	})
+}
+
+func TestParseSizedInts(t *testing.T) {
+	t.Run("parses valid values", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			parse    func(string) (interface{}, error)
+			raw      string
+			expected interface{}
+		}{
+			{name: "int8", parse: ParseInt8, raw: "-128", expected: int8(-128)},
+			{name: "int16", parse: ParseInt16, raw: "32767", expected: int16(32767)},
+			{name: "int32", parse: ParseInt32, raw: "-5", expected: int32(-5)},
+			{name: "int64", parse: ParseInt64, raw: "9223372036854775807", expected: int64(9223372036854775807)},
+			{name: "uint", parse: ParseUint, raw: "42", expected: uint(42)},
+			{name: "uint8", parse: ParseUint8, raw: "255", expected: uint8(255)},
+			{name: "uint16", parse: ParseUint16, raw: "65535", expected: uint16(65535)},
+			{name: "uint32", parse: ParseUint32, raw: "7", expected: uint32(7)},
+			{name: "uint64", parse: ParseUint64, raw: "18446744073709551615", expected: uint64(18446744073709551615)},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				res, err := tc.parse(tc.raw)
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, res)
+			})
+		}
+	})
+
+	t.Run("returns error for values out of range", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			parse    func(string) (interface{}, error)
+			raw      string
+			expected string
+		}{
+			{name: "int8", parse: ParseInt8, raw: "128", expected: "128 is out of range for int8 (-128 to 127)"},
+			{name: "int16", parse: ParseInt16, raw: "-32769", expected: "-32769 is out of range for int16 (-32768 to 32767)"},
+			{name: "int32", parse: ParseInt32, raw: "2147483648", expected: "2147483648 is out of range for int32 (-2147483648 to 2147483647)"},
+			{name: "uint8", parse: ParseUint8, raw: "256", expected: "256 is out of range for uint8 (0 to 255)"},
+			{name: "negative uint", parse: ParseUint16, raw: "-1", expected: "-1 is out of range for uint16 (0 to 65535)"},
+			{name: "uint64", parse: ParseUint64, raw: "18446744073709551616", expected: "18446744073709551616 is out of range for uint64 (0 to 18446744073709551615)"},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := tc.parse(tc.raw)
+
+				require.EqualError(t, err, tc.expected)
+			})
+		}
+	})
+
+	t.Run("returns error for invalid syntax", func(t *testing.T) {
+		_, err := ParseUint32("abc")
+
+		require.EqualError(t, err, `strconv.ParseUint: parsing "abc": invalid syntax`)
+	})
+}
+
+func TestParseFloats(t *testing.T) {
+	t.Run("parses valid float32", func(t *testing.T) {
+		res, err := ParseFloat32("1.5")
+
+		require.NoError(t, err)
+		require.Equal(t, float32(1.5), res)
+	})
+
+	t.Run("parses valid float64", func(t *testing.T) {
+		res, err := ParseFloat64("-2.25e3")
+
+		require.NoError(t, err)
+		require.Equal(t, -2250.0, res)
+	})
+
+	t.Run("returns error for float32 out of range", func(t *testing.T) {
+		_, err := ParseFloat32("1e39")
+
+		require.EqualError(t, err, "1e39 is out of range for float32")
+	})
+
+	t.Run("returns error for invalid float", func(t *testing.T) {
+		_, err := ParseFloat64("abc")
+
+		require.EqualError(t, err, `strconv.ParseFloat: parsing "abc": invalid syntax`)
+	})
+}
+
+func TestParseBool(t *testing.T) {
+	t.Run("parses default vocabulary", func(t *testing.T) {
+		cases := []struct {
+			raw      string
+			expected bool
+		}{
+			{raw: "true", expected: true},
+			{raw: "Yes", expected: true},
+			{raw: "ON", expected: true},
+			{raw: "1", expected: true},
+			{raw: "✓", expected: true},
+			{raw: "False", expected: false},
+			{raw: "no", expected: false},
+			{raw: "off", expected: false},
+			{raw: "0", expected: false},
+			{raw: "✗", expected: false},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.raw, func(t *testing.T) {
+				res, err := ParseBool(tc.raw)
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, res)
+			})
+		}
+	})
+
+	t.Run("returns error for unknown word", func(t *testing.T) {
+		_, err := ParseBool("maybe")
+
+		require.EqualError(t, err, "unrecognized boolean maybe, expected one of true, yes, on, 1, ✓, false, no, off, 0, ✗")
+	})
+
+	t.Run("parses extended vocabulary", func(t *testing.T) {
+		vocabulary := DefaultBoolVocabulary.Extend([]string{"ja"}, []string{"nein"})
+
+		res, err := vocabulary.Parse("Nein")
+
+		require.NoError(t, err)
+		assert.Equal(t, false, res)
+		assert.Len(t, DefaultBoolVocabulary.False, 5)
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Run("parses supported formats", func(t *testing.T) {
+		cases := []struct {
+			raw      string
+			expected time.Duration
+		}{
+			{raw: "1h30m", expected: 90 * time.Minute},
+			{raw: "250ms", expected: 250 * time.Millisecond},
+			{raw: "90 seconds", expected: 90 * time.Second},
+			{raw: "1 minute", expected: time.Minute},
+			{raw: "2 days", expected: 48 * time.Hour},
+			{raw: "1.5 hours", expected: 90 * time.Minute},
+			{raw: "1 week", expected: 7 * 24 * time.Hour},
+			{raw: "1 hour and 30 mins", expected: 90 * time.Minute},
+			{raw: "2 Hours, 5 Seconds", expected: 2*time.Hour + 5*time.Second},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.raw, func(t *testing.T) {
+				res, err := ParseDuration(tc.raw)
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, res)
+			})
+		}
+	})
+
+	t.Run("returns error for unknown unit", func(t *testing.T) {
+		_, err := ParseDuration("3 fortnights")
+
+		require.EqualError(t, err, "unrecognized duration unit fortnights in 3 fortnights")
+	})
+
+	t.Run("returns error for invalid duration", func(t *testing.T) {
+		_, err := ParseDuration("soon")
+
+		require.EqualError(t, err, "unrecognized duration soon")
+	})
+
+	t.Run("returns error for duration out of range", func(t *testing.T) {
+		_, err := ParseDuration("1000 weeks and 100000 weeks")
+
+		require.EqualError(t, err, "1000 weeks and 100000 weeks is out of range for time.Duration")
+	})
+}
+
+func TestParseTextUnmarshaler(t *testing.T) {
+	t.Run("parses type with pointer receiver", func(t *testing.T) {
+		res, err := ParseTextUnmarshaler(reflect.TypeOf(net.IP{}), "192.168.0.1")
+
+		require.NoError(t, err)
+		assert.Equal(t, net.ParseIP("192.168.0.1"), res)
+	})
+
+	t.Run("parses pointer type", func(t *testing.T) {
+		res, err := ParseTextUnmarshaler(reflect.TypeOf(&net.IP{}), "10.0.0.1")
+
+		require.NoError(t, err)
+		assert.Equal(t, net.ParseIP("10.0.0.1"), *res.(*net.IP))
+	})
+
+	t.Run("returns unmarshaling error", func(t *testing.T) {
+		_, err := ParseTextUnmarshaler(reflect.TypeOf(net.IP{}), "nope")
+
+		require.EqualError(t, err, "invalid IP address: nope")
+	})
+
+	t.Run("returns error for type that is not an unmarshaler", func(t *testing.T) {
+		_, err := ParseTextUnmarshaler(reflect.TypeOf(0), "1")
+
+		require.EqualError(t, err, "int does not implement encoding.TextUnmarshaler")
+	})
+}
+
+func TestParseJSON(t *testing.T) {
+	type payload struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	t.Run("decodes object", func(t *testing.T) {
+		res, err := ParseJSON(reflect.TypeOf(payload{}), `{"name": "John", "tags": ["a"]}`)
+
+		require.NoError(t, err)
+		assert.Equal(t, payload{Name: "John", Tags: []string{"a"}}, res)
+	})
+
+	t.Run("decodes invalid JSON as string", func(t *testing.T) {
+		res, err := ParseJSON(reflect.TypeOf(""), `John`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "John", res)
+	})
+
+	t.Run("returns error for mismatched type", func(t *testing.T) {
+		_, err := ParseJSON(reflect.TypeOf(payload{}), `[1]`)
+
+		require.EqualError(t, err, "json: cannot unmarshal array into Go value of type defaults.payload")
+	})
+}
+
+func TestParseBigNumbers(t *testing.T) {
+	t.Run("parses big.Int", func(t *testing.T) {
+		res, err := ParseBigInt("123456789012345678901234567890")
+
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012345678901234567890", res.(*big.Int).String())
+	})
+
+	t.Run("parses big.Float with the digits written", func(t *testing.T) {
+		res, err := ParseBigFloat("1234567890123456789.0123456789")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1234567890123456789.0123456789", res.(*big.Float).Text('f', 10))
+	})
+
+	t.Run("parses big.Rat from decimals and fractions", func(t *testing.T) {
+		res, err := ParseBigRat("12.50")
+		require.NoError(t, err)
+		assert.Equal(t, "25/2", res.(*big.Rat).String())
+
+		res, err = ParseBigRat("1/3")
+		require.NoError(t, err)
+		assert.Equal(t, "1/3", res.(*big.Rat).String())
+	})
+
+	t.Run("returns errors for invalid numbers", func(t *testing.T) {
+		_, err := ParseBigInt("1.5")
+		require.EqualError(t, err, "1.5 is not a valid big.Int")
+
+		_, err = ParseBigFloat("abc")
+		require.EqualError(t, err, "abc is not a valid big.Float")
+
+		_, err = ParseBigRat("abc")
+		require.EqualError(t, err, "abc is not a valid big.Rat")
+	})
+}
+
+func TestParseBytes(t *testing.T) {
+	t.Run("parses supported encodings", func(t *testing.T) {
+		cases := []struct {
+			raw      string
+			expected []byte
+		}{
+			{raw: "hex:0aff10", expected: []byte{0x0a, 0xff, 0x10}},
+			{raw: "base64:CgsM", expected: []byte{0x0a, 0x0b, 0x0c}},
+			{raw: "base64:Cg", expected: []byte{0x0a}},
+			{raw: "plain", expected: []byte("plain")},
+			{raw: "text:hex:ff", expected: []byte("hex:ff")},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.raw, func(t *testing.T) {
+				res, err := ParseBytes(tc.raw)
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, res)
+			})
+		}
+	})
+
+	t.Run("returns error for invalid hex", func(t *testing.T) {
+		_, err := ParseBytes("hex:zz")
+
+		require.EqualError(t, err, "invalid hex value hex:zz: encoding/hex: invalid byte: U+007A 'z'")
+	})
+
+	t.Run("returns error for invalid base64", func(t *testing.T) {
+		_, err := ParseBytes("base64:!!")
+
+		require.EqualError(t, err, "invalid base64 value base64:!!")
+	})
+}
+
+func TestParseNetworkTypes(t *testing.T) {
+	t.Run("parses IP", func(t *testing.T) {
+		res, err := ParseIP(" 10.0.0.1 ")
+		require.NoError(t, err)
+		assert.Equal(t, net.ParseIP("10.0.0.1"), res)
+
+		_, err = ParseIP("10.0.0")
+		require.EqualError(t, err, "invalid IP address 10.0.0")
+	})
+
+	t.Run("parses IPNet", func(t *testing.T) {
+		res, err := ParseIPNet("10.1.2.3/8")
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/8", res.(*net.IPNet).String())
+
+		_, err = ParseIPNet("10.0.0.0")
+		require.EqualError(t, err, "invalid CIDR address: 10.0.0.0")
+	})
+
+	t.Run("parses hardware address", func(t *testing.T) {
+		res, err := ParseHardwareAddr("00:1A:2b:3c:4d:5e")
+		require.NoError(t, err)
+		assert.Equal(t, "00:1a:2b:3c:4d:5e", res.(net.HardwareAddr).String())
+	})
+
+	t.Run("parses URL", func(t *testing.T) {
+		res, err := ParseURL("https://example.com/a?b=c")
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", res.(*url.URL).Host)
+	})
+
+	t.Run("parses mail address", func(t *testing.T) {
+		res, err := ParseMailAddress("John Doe <john@example.com>")
+		require.NoError(t, err)
+		assert.Equal(t, &mail.Address{Name: "John Doe", Address: "john@example.com"}, res)
+	})
 }
 
 func TestParseTime(t *testing.T) {
@@ -62,3 +418,92 @@ func TestParseTime(t *testing.T) {
 		require.EqualError(t, err, "unrecognized time format abc")
 	})
 }
+
+func TestTimeParserLayouts(t *testing.T) {
+	t.Run("parses date only values by default", func(t *testing.T) {
+		res, err := ParseTime("2020-11-05")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 11, 5, 0, 0, 0, 0, time.UTC), res)
+	})
+
+	t.Run("parses custom layouts", func(t *testing.T) {
+		parser := TimeParser{Layouts: []string{"02/01/2006"}}
+
+		res, err := parser.Parse("05/11/2020")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 11, 5, 0, 0, 0, 0, time.UTC), res)
+
+		_, err = parser.Parse("2020-11-05")
+		require.EqualError(t, err, "unrecognized time format 2020-11-05")
+	})
+
+	t.Run("uses location for values without time zone", func(t *testing.T) {
+		location := time.FixedZone("CET", 3600)
+		parser := TimeParser{Location: location}
+
+		res, err := parser.Parse("2020-11-05 16:01")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 11, 5, 15, 1, 0, 0, time.UTC), res.(time.Time).UTC())
+
+		res, err = parser.Parse("2020-11-05T16:01:54Z")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 11, 5, 16, 1, 54, 0, time.UTC), res.(time.Time).UTC())
+	})
+
+	t.Run("uses location for relative expressions", func(t *testing.T) {
+		now := time.Date(2020, 11, 5, 23, 30, 0, 0, time.UTC)
+		parser := TimeParser{Now: func() time.Time { return now }, Location: time.FixedZone("CET", 3600)}
+
+		res, err := parser.Parse("today")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 11, 5, 23, 0, 0, 0, time.UTC), res.(time.Time).UTC())
+	})
+}
+
+func TestTimeParserRelative(t *testing.T) {
+	now := time.Date(2020, 11, 5, 16, 1, 54, 0, time.UTC)
+	parser := TimeParser{Now: func() time.Time { return now }}
+
+	t.Run("resolves expressions against the clock", func(t *testing.T) {
+		cases := []struct {
+			raw      string
+			expected time.Time
+		}{
+			{raw: "now", expected: now},
+			{raw: "Today", expected: time.Date(2020, 11, 5, 0, 0, 0, 0, time.UTC)},
+			{raw: "yesterday 09:00", expected: time.Date(2020, 11, 4, 9, 0, 0, 0, time.UTC)},
+			{raw: "tomorrow 23:59:59", expected: time.Date(2020, 11, 6, 23, 59, 59, 0, time.UTC)},
+			{raw: "in 3 days", expected: time.Date(2020, 11, 8, 16, 1, 54, 0, time.UTC)},
+			{raw: "2 hours ago", expected: time.Date(2020, 11, 5, 14, 1, 54, 0, time.UTC)},
+			{raw: "90m from now", expected: time.Date(2020, 11, 5, 17, 31, 54, 0, time.UTC)},
+			{raw: "start of day", expected: time.Date(2020, 11, 5, 0, 0, 0, 0, time.UTC)},
+			{raw: "start of week", expected: time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)},
+			{raw: "start of month", expected: time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC)},
+			{raw: "start of next month", expected: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)},
+			{raw: "end of month", expected: time.Date(2020, 11, 30, 23, 59, 59, 999999999, time.UTC)},
+			{raw: "end of last year", expected: time.Date(2019, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.raw, func(t *testing.T) {
+				res, err := parser.Parse(tc.raw)
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, res)
+			})
+		}
+	})
+
+	t.Run("returns error for invalid expressions", func(t *testing.T) {
+		_, err := parser.Parse("in a while")
+		require.EqualError(t, err, "unrecognized time expression in a while: unrecognized duration a while")
+
+		_, err = parser.Parse("start of decade")
+		require.EqualError(t, err, "unrecognized time expression start of decade: unknown period decade")
+
+		_, err = parser.Parse("today at noon")
+		require.EqualError(t, err, "unrecognized time expression today at noon: unrecognized time of day at noon")
+	})
+}
@@ -0,0 +1,72 @@
+package assistdog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RegisterEnum registers a parser and a comparer for an enum type, so that its constants can be
+// written by name in tables. Names are matched case-insensitively, and comparison failures report
+// values by name. The type is taken from zeroValue, and every value in the map must be convertible to it.
+// If a previous parser or comparer already exists for the type, it will be replaced.
+func (a *Assist) RegisterEnum(zeroValue interface{}, values map[string]interface{}) {
+	tp := reflect.TypeOf(zeroValue)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	byName := map[string]interface{}{}
+	nameOf := map[interface{}]string{}
+	for _, name := range names {
+		v := reflect.ValueOf(values[name])
+		if !v.IsValid() || !v.Type().ConvertibleTo(tp) {
+			panic(fmt.Sprintf("assistdog: enum value %v of %v cannot be converted to %v", name, reflect.TypeOf(values[name]), tp))
+		}
+
+		value := v.Convert(tp).Interface()
+		byName[strings.ToLower(name)] = value
+		if _, ok := nameOf[value]; !ok {
+			nameOf[value] = name
+		}
+	}
+
+	parse := func(raw string) (interface{}, error) {
+		value, ok := byName[strings.ToLower(strings.TrimSpace(raw))]
+		if !ok {
+			return nil, fmt.Errorf("unknown %v %v, expected one of %v", tp, raw, strings.Join(names, ", "))
+		}
+
+		return value, nil
+	}
+
+	describeEnum := func(value interface{}) interface{} {
+		if name, ok := nameOf[value]; ok {
+			return name
+		}
+
+		return value
+	}
+
+	a.RegisterParser(zeroValue, parse)
+	a.RegisterComparer(zeroValue, func(raw string, actual interface{}) error {
+		if reflect.TypeOf(actual) != tp {
+			return fmt.Errorf("%v is not %v", actual, tp)
+		}
+
+		expected, err := parse(raw)
+		if err != nil {
+			return err
+		}
+
+		if expected != actual {
+			return fmt.Errorf("expected %v, but got %v", describeEnum(expected), describeEnum(actual))
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package assistdog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shipmentStatus int
+
+const (
+	pending shipmentStatus = iota + 1
+	shipped
+	delivered
+)
+
+type shipment struct {
+	Status   shipmentStatus
+	Previous *shipmentStatus
+}
+
+func newEnumAssist() *Assist {
+	assist := NewDefault()
+	assist.RegisterEnum(shipmentStatus(0), map[string]interface{}{
+		"Pending":   pending,
+		"Shipped":   shipped,
+		"Delivered": delivered,
+	})
+
+	return assist
+}
+
+func TestEnums(t *testing.T) {
+	t.Run("creates enums by name", func(t *testing.T) {
+		result, err := newEnumAssist().CreateInstance(new(shipment), buildTable([][]string{
+			{"Status", "shipped"},
+			{"Previous", "PENDING"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*shipment)
+		assert.Equal(t, shipped, typed.Status)
+		require.NotNil(t, typed.Previous)
+		assert.Equal(t, pending, *typed.Previous)
+	})
+
+	t.Run("lists valid options for unknown names", func(t *testing.T) {
+		_, err := newEnumAssist().CreateInstance(new(shipment), buildTable([][]string{{"Status", "Lost"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.shipment:
+- Status: unknown assistdog.shipmentStatus Lost, expected one of Delivered, Pending, Shipped`)
+	})
+
+	t.Run("reports comparison failures by name", func(t *testing.T) {
+		err := newEnumAssist().CompareToInstance(&shipment{Status: pending}, buildTable([][]string{{"Status", "Shipped"}}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Status: expected Shipped, but got Pending`)
+	})
+
+	t.Run("reports unnamed values by number", func(t *testing.T) {
+		err := newEnumAssist().CompareToInstance(&shipment{Status: 7}, buildTable([][]string{{"Status", "Shipped"}}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Status: expected Shipped, but got 7`)
+	})
+
+	t.Run("accepts untyped constants", func(t *testing.T) {
+		assist := NewDefault()
+		assist.RegisterEnum(shipmentStatus(0), map[string]interface{}{"Pending": 1})
+
+		err := assist.CompareToInstance(&shipment{Status: pending}, buildTable([][]string{{"Status", "pending"}}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("panics for values of other types", func(t *testing.T) {
+		require.PanicsWithValue(t, "assistdog: enum value Pending of string cannot be converted to assistdog.shipmentStatus", func() {
+			NewDefault().RegisterEnum(shipmentStatus(0), map[string]interface{}{"Pending": "pending"})
+		})
+	})
+}
@@ -0,0 +1,724 @@
+package assistdog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rdumont/assistdog/defaults"
+)
+
+// structField is a struct field along with the struct type it was looked up in.
+type structField struct {
+	reflect.StructField
+	owner reflect.Type
+}
+
+// fieldKey identifies a field of a struct type for the parsers and comparers registered for it.
+type fieldKey struct {
+	owner reflect.Type
+	name  string
+}
+
+type pathSegment struct {
+	name    string
+	indexes []int
+}
+
+// lookupField resolves a header into the field it refers to. Headers can be dotted paths,
+// such as Address.City, to reach the fields of nested structs, and can index slices and arrays,
+// such as Phones[0].Number. When alloc is true, nil pointers to intermediate structs are allocated
+// and slices are grown along the way. Otherwise, they are reported as errors.
+// The struct field the last segment refers to is returned along with the value, so that its tag
+// and the parsers and comparers registered for it can be found.
+func (a *Assist) lookupField(root reflect.Value, header string, alloc bool) (reflect.Value, structField, error) {
+	segments, err := parsePath(header)
+	if err != nil {
+		return reflect.Value{}, structField{}, err
+	}
+
+	v := root
+	path := ""
+	var field structField
+	for _, segment := range segments {
+		v, err = indirect(v, path, alloc)
+		if err != nil {
+			return reflect.Value{}, structField{}, err
+		}
+
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, structField{}, fmt.Errorf("%v is not a struct", path)
+		}
+
+		sf, ok := a.findStructField(v.Type(), segment.name)
+		if !ok {
+			return reflect.Value{}, structField{}, a.fieldNotFound(v.Type(), segment.name)
+		}
+
+		field = structField{StructField: sf, owner: v.Type()}
+
+		v = v.FieldByIndex(field.Index)
+		path = joinPath(path, segment.name)
+		for _, index := range segment.indexes {
+			v, err = indirect(v, path, alloc)
+			if err != nil {
+				return reflect.Value{}, structField{}, err
+			}
+
+			v, err = indexValue(v, index, path, alloc)
+			if err != nil {
+				return reflect.Value{}, structField{}, err
+			}
+
+			path = fmt.Sprintf("%v[%v]", path, index)
+		}
+	}
+
+	return v, field, nil
+}
+
+// fieldTag holds the options of a field's assist tag, such as
+// `assist:"First Name,alias=fname,format=2006-01-02,required"`. The first value is the header
+// for the field, which defaults to its name, and is followed by options:
+//
+//	alias=name    another header for the field, which can be repeated
+//	format=layout the layout of time.Time fields, instead of the configured ones
+//	ignore        makes headers for the field fail, so that it's neither set nor compared
+//	required      makes CreateInstance and CreateSlice fail for tables without the field
+//	string=modes  the string comparison modes of string fields, such as fold|trim
+//
+// Values that contain commas, such as layouts, can be wrapped in single quotes, as in
+// format='Mon, 02 Jan 2006'. Unknown options, such as misspelled ones, fail the field like bad values do.
+// Fields without an assist tag use the name in their json tag as their header.
+type fieldTag struct {
+	name     string
+	aliases  []string
+	format   string
+	ignore   bool
+	required bool
+	options  map[string]string
+	err      error
+}
+
+func parseTag(field reflect.StructField) fieldTag {
+	tag := fieldTag{options: map[string]string{}}
+	raw, ok := field.Tag.Lookup("assist")
+	if !ok {
+		if raw, ok = field.Tag.Lookup("json"); ok && raw != "-" {
+			tag.name = strings.Split(raw, ",")[0]
+		}
+
+		return tag
+	}
+
+	if raw == "-" {
+		tag.ignore = true
+		return tag
+	}
+
+	parts := splitTag(raw)
+	tag.name = strings.TrimSpace(parts[0])
+	for _, option := range parts[1:] {
+		key, value := strings.TrimSpace(option), ""
+		if i := strings.Index(key, "="); i >= 0 {
+			key, value = key[:i], key[i+1:]
+		}
+
+		switch key {
+		case "alias":
+			tag.aliases = append(tag.aliases, value)
+		case "format":
+			tag.format = value
+		case "ignore":
+			tag.ignore = true
+		case "required":
+			tag.required = true
+		case "string":
+			tag.options[key] = value
+		default:
+			if tag.err == nil {
+				tag.err = fmt.Errorf("unknown tag option %v", key)
+			}
+		}
+	}
+
+	return tag
+}
+
+// splitTag splits a tag into its header name and options on commas, except within option
+// values wrapped in single quotes right after the =, whose quotes are removed.
+// Quotes anywhere else, such as in the header name, are kept as they are.
+func splitTag(raw string) []string {
+	parts := strings.SplitN(raw, ",", 2)
+	if len(parts) == 1 {
+		return parts
+	}
+
+	split := []string{parts[0]}
+	var current strings.Builder
+	quoted := false
+	runes := []rune(parts[1])
+	for i, r := range runes {
+		switch {
+		case r == '\'' && !quoted && i > 0 && runes[i-1] == '=':
+			quoted = true
+		case r == '\'' && quoted && (i == len(runes)-1 || runes[i+1] == ','):
+			quoted = false
+		case r == ',' && !quoted:
+			split = append(split, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	return append(split, current.String())
+}
+
+// headers returns the headers that refer to a field, starting with the preferred one.
+func (t fieldTag) headers(field reflect.StructField) []string {
+	headers := []string{}
+	if t.name != "" {
+		headers = append(headers, t.name)
+	}
+
+	headers = append(headers, t.aliases...)
+	if t.name != field.Name {
+		headers = append(headers, field.Name)
+	}
+
+	return headers
+}
+
+// NormalizeHeader is the default header normalizer, which ignores case, spaces, underscores and hyphens,
+// so that "first name", "First_Name" and "FirstName" all refer to the same field.
+func NormalizeHeader(header string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-':
+			return -1
+		default:
+			return unicode.ToLower(r)
+		}
+	}, header)
+}
+
+// SetHeaderNormalizer replaces the function used to normalize headers and field names before
+// matching them, when a header doesn't match any field exactly. Passing nil restores NormalizeHeader.
+func (a *Assist) SetHeaderNormalizer(normalizer func(header string) string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.headerNormalizer = normalizer
+}
+
+func (a *Assist) currentHeaderNormalizer() func(string) string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if a.headerNormalizer == nil {
+		return NormalizeHeader
+	}
+
+	return a.headerNormalizer
+}
+
+// findStructField looks for the field of a struct type a header refers to, by the names and aliases
+// in the tags of its direct fields, then by the names of its direct and promoted fields and,
+// failing that, by comparing the normalized header with the normalized headers of its direct fields.
+func (a *Assist) findStructField(tp reflect.Type, header string) (reflect.StructField, bool) {
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		tag := parseTag(field)
+		if tag.name != "" && tag.name == header {
+			return field, true
+		}
+
+		for _, alias := range tag.aliases {
+			if alias == header {
+				return field, true
+			}
+		}
+	}
+
+	if field, ok := tp.FieldByName(header); ok {
+		return field, true
+	}
+
+	normalize := a.currentHeaderNormalizer()
+	normalized := normalize(header)
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		for _, candidate := range parseTag(field).headers(field) {
+			if normalize(candidate) == normalized {
+				return field, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// fieldNotFound reports a header that doesn't refer to any field of a struct type,
+// suggesting the fields with the closest normalized headers.
+func (a *Assist) fieldNotFound(tp reflect.Type, header string) error {
+	normalize := a.currentHeaderNormalizer()
+	normalized := normalize(header)
+	maxDistance := len([]rune(normalized)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	type suggestion struct {
+		header   string
+		distance int
+	}
+
+	suggestions := []suggestion{}
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		tag := parseTag(field)
+		if field.PkgPath != "" || tag.ignore {
+			continue
+		}
+
+		best := -1
+		for _, candidate := range tag.headers(field) {
+			if d := editDistance(normalized, normalize(candidate)); best < 0 || d < best {
+				best = d
+			}
+		}
+
+		if best <= maxDistance {
+			suggestions = append(suggestions, suggestion{header: tag.headers(field)[0], distance: best})
+		}
+	}
+
+	if len(suggestions) == 0 {
+		return fmt.Errorf("field not found")
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.header
+	}
+
+	if len(names) == 1 {
+		return fmt.Errorf("field not found, did you mean %v?", names[0])
+	}
+
+	return fmt.Errorf("field not found, did you mean %v or %v?", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters needed to turn one string into another.
+func editDistance(s, t string) int {
+	a, b := []rune(s), []rune(t)
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = d[i-1][j-1] + cost
+			if d[i-1][j]+1 < d[i][j] {
+				d[i][j] = d[i-1][j] + 1
+			}
+
+			if d[i][j-1]+1 < d[i][j] {
+				d[i][j] = d[i][j-1] + 1
+			}
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+// missingRequired returns the headers of the required fields of a struct type that none of the
+// given headers refer to, including the fields of embedded structs and of the nested structs
+// the headers reach, such as the fields of Address for Address.City.
+func (a *Assist) missingRequired(tp reflect.Type, headers []string) []string {
+	found := map[string]bool{}
+	nested := map[string][]string{}
+	prefixes := map[string]string{}
+	types := map[string]reflect.Type{}
+	order := []string{}
+	for _, header := range headers {
+		segments, err := parsePath(header)
+		if err != nil {
+			continue
+		}
+
+		field, ok := a.findStructField(tp, segments[0].name)
+		if !ok {
+			continue
+		}
+
+		found[fmt.Sprint(field.Index)] = true
+		elem, ok := nestedStruct(field.Type, len(segments[0].indexes))
+		if len(segments) == 1 || !ok {
+			continue
+		}
+
+		dot := strings.Index(header, ".")
+		key := fmt.Sprint(field.Index, segments[0].indexes)
+		if _, ok := nested[key]; !ok {
+			order = append(order, key)
+			prefixes[key] = header[:dot]
+			types[key] = elem
+		}
+
+		nested[key] = append(nested[key], header[dot+1:])
+	}
+
+	missing := []string{}
+	for _, field := range requiredFields(tp, nil, map[reflect.Type]bool{}) {
+		header := parseTag(field).headers(field)[0]
+		if len(field.Index) > 1 {
+			// Promoted fields are only found by their names, unless other fields shadow them.
+			if promoted, ok := tp.FieldByName(field.Name); !ok || fmt.Sprint(promoted.Index) != fmt.Sprint(field.Index) {
+				continue
+			}
+
+			header = field.Name
+		}
+
+		if !found[fmt.Sprint(field.Index)] {
+			missing = append(missing, header)
+		}
+	}
+
+	for _, key := range order {
+		for _, header := range a.missingRequired(types[key], nested[key]) {
+			missing = append(missing, prefixes[key]+"."+header)
+		}
+	}
+
+	return missing
+}
+
+// requiredFields returns the required fields of a struct type and of its embedded structs,
+// with their indexes from the outermost struct.
+func requiredFields(tp reflect.Type, index []int, visited map[reflect.Type]bool) []reflect.StructField {
+	visited[tp] = true
+	fields := []reflect.StructField{}
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		field.Index = append(append([]int{}, index...), i)
+		if parseTag(field).required {
+			fields = append(fields, field)
+		}
+
+		if embedded, ok := nestedStruct(field.Type, 0); field.Anonymous && ok && !visited[embedded] {
+			fields = append(fields, requiredFields(embedded, field.Index, visited)...)
+		}
+	}
+
+	return fields
+}
+
+// nestedStruct returns the struct type reached from a field's type by following pointers
+// and indexing a number of times into slices and arrays.
+func nestedStruct(tp reflect.Type, indexes int) (reflect.Type, bool) {
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+
+	for ; indexes > 0; indexes-- {
+		if tp.Kind() != reflect.Slice && tp.Kind() != reflect.Array {
+			return nil, false
+		}
+
+		tp = tp.Elem()
+		for tp.Kind() == reflect.Ptr {
+			tp = tp.Elem()
+		}
+	}
+
+	return tp, tp.Kind() == reflect.Struct
+}
+
+// RegisterFieldParser registers a value parser for a field of a struct type, which takes precedence
+// over the parsers registered for the field's type. The struct type can be given as a value or a pointer,
+// and the field by its name or any header that refers to it.
+// If a previous parser already exists for the given field, it will be replaced.
+func (a *Assist) RegisterFieldParser(i interface{}, field string, parser ParseFunc) {
+	key := a.fieldKey(i, field)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.fieldParsers == nil {
+		a.fieldParsers = map[fieldKey]ParseFunc{}
+	}
+
+	a.fieldParsers[key] = parser
+}
+
+// RegisterFieldComparer registers a value comparer for a field of a struct type, which takes precedence
+// over the comparers registered for the field's type. The struct type can be given as a value or a pointer,
+// and the field by its name or any header that refers to it.
+// If a previous comparer already exists for the given field, it will be replaced.
+func (a *Assist) RegisterFieldComparer(i interface{}, field string, comparer CompareFunc) {
+	key := a.fieldKey(i, field)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.fieldComparers == nil {
+		a.fieldComparers = map[fieldKey]CompareFunc{}
+	}
+
+	a.fieldComparers[key] = comparer
+}
+
+// RemoveFieldParser removes the value parser for a field of a struct type.
+func (a *Assist) RemoveFieldParser(i interface{}, field string) {
+	key := a.fieldKey(i, field)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.fieldParsers, key)
+}
+
+// RemoveFieldComparer removes the value comparer for a field of a struct type.
+func (a *Assist) RemoveFieldComparer(i interface{}, field string) {
+	key := a.fieldKey(i, field)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.fieldComparers, key)
+}
+
+func (a *Assist) fieldKey(i interface{}, header string) fieldKey {
+	tp := reflect.TypeOf(i)
+	if tp != nil && tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+
+	if tp == nil || tp.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("assistdog: expected a struct or a pointer to one, but got %v", reflect.TypeOf(i)))
+	}
+
+	field, ok := a.findStructField(tp, header)
+	if !ok {
+		panic(fmt.Sprintf("assistdog: %v has no field %v", tp, header))
+	}
+
+	return fieldKey{owner: tp, name: field.Name}
+}
+
+// fieldParser returns the parser registered for a field or asked for in its tag,
+// or nil if it should use the parser of its type.
+func (a *Assist) fieldParser(field structField, tp reflect.Type) (ParseFunc, error) {
+	tag := parseTag(field.StructField)
+	if tag.err != nil {
+		return nil, tag.err
+	}
+
+	if tp == field.Type {
+		a.lock.RLock()
+		p, ok := a.fieldParsers[fieldKey{owner: field.owner, name: field.Name}]
+		tokens := a.currentNullTokens()
+		a.lock.RUnlock()
+		if ok {
+			return nullableParser(tp, p, tokens), nil
+		}
+	}
+
+	if tag.format == "" {
+		return nil, nil
+	}
+
+	times, err := a.formatTimeParser(tag.format, tp)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := a.nullTokensSnapshot()
+	if tp.Kind() == reflect.Ptr {
+		return nullableParser(tp, pointerParser(tp, times.Parse), tokens), nil
+	}
+
+	return times.Parse, nil
+}
+
+// fieldComparer returns the comparer registered for a field or asked for in its tag,
+// or nil if it should use the comparer of its type.
+func (a *Assist) fieldComparer(field structField, tp reflect.Type) (CompareFunc, error) {
+	tag := parseTag(field.StructField)
+	if tag.err != nil {
+		return nil, tag.err
+	}
+
+	if tp == field.Type {
+		a.lock.RLock()
+		c, ok := a.fieldComparers[fieldKey{owner: field.owner, name: field.Name}]
+		tokens := a.currentNullTokens()
+		a.lock.RUnlock()
+		if ok {
+			return nullableComparer(tp, c, tokens), nil
+		}
+	}
+
+	if tag.format != "" {
+		times, err := a.formatTimeParser(tag.format, tp)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens := a.nullTokensSnapshot()
+		if tp.Kind() == reflect.Ptr {
+			return nullableComparer(tp, pointerComparer(times.Compare), tokens), nil
+		}
+
+		return times.Compare, nil
+	}
+
+	rawMode, ok := tag.options["string"]
+	if !ok || tp.Kind() != reflect.String {
+		return nil, nil
+	}
+
+	mode, err := defaults.ParseStringMode(rawMode)
+	if err != nil {
+		return nil, err
+	}
+
+	c := defaults.StringComparer{Mode: mode}
+	return func(raw string, actual interface{}) error {
+		return c.Compare(raw, reflect.ValueOf(actual).String())
+	}, nil
+}
+
+// formatTimeParser returns the configured time parser with its layouts replaced by a field's format.
+// Formats are only supported for time.Time fields and pointers to them.
+func (a *Assist) formatTimeParser(format string, tp reflect.Type) (defaults.TimeParser, error) {
+	timeType := reflect.TypeOf(time.Time{})
+	if tp != timeType && tp != reflect.PtrTo(timeType) {
+		return defaults.TimeParser{}, fmt.Errorf("format is not supported for %v", tp)
+	}
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	times := a.currentTimeParser()
+	times.Layouts = []string{format}
+	return times, nil
+}
+
+func (a *Assist) nullTokensSnapshot() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.currentNullTokens()
+}
+
+// indirect follows pointers, allocating them if they are nil and alloc is true.
+func indirect(v reflect.Value, path string, alloc bool) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !alloc || !v.CanSet() {
+				return reflect.Value{}, fmt.Errorf("%v is nil", path)
+			}
+
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		v = v.Elem()
+	}
+
+	return v, nil
+}
+
+// indexValue returns the element at an index of a slice or array, growing slices
+// to fit the index if alloc is true.
+func indexValue(v reflect.Value, index int, path string, alloc bool) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Slice:
+		if index >= v.Len() {
+			if !alloc || !v.CanSet() {
+				return reflect.Value{}, fmt.Errorf("index %v out of range for %v (length %v)", index, path, v.Len())
+			}
+
+			grown := reflect.MakeSlice(v.Type(), index+1, index+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+	case reflect.Array:
+		if index >= v.Len() {
+			return reflect.Value{}, fmt.Errorf("index %v out of range for %v (length %v)", index, path, v.Len())
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("%v is not a slice or array", path)
+	}
+
+	return v.Index(index), nil
+}
+
+// parsePath splits a header into its dotted segments and their indexes.
+func parsePath(header string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	for _, part := range strings.Split(header, ".") {
+		segment := pathSegment{name: part}
+		if open := strings.Index(part, "["); open >= 0 {
+			segment.name = part[:open]
+			rest := part[open:]
+			for rest != "" {
+				end := strings.Index(rest, "]")
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("invalid index in %v", part)
+				}
+
+				index, err := strconv.Atoi(rest[1:end])
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %v in %v", rest[1:end], part)
+				}
+
+				segment.indexes = append(segment.indexes, index)
+				rest = rest[end+1:]
+			}
+		}
+
+		if segment.name == "" {
+			return nil, fmt.Errorf("invalid header %q", header)
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// sortedHeaders returns the headers of a table in order, so that parents such as Address
+// are handled before their nested fields such as Address.City.
+func sortedHeaders(table map[string]string) []string {
+	headers := make([]string, 0, len(table))
+	for header := range table {
+		headers = append(headers, header)
+	}
+
+	sort.Strings(headers)
+	return headers
+}
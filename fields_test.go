@@ -0,0 +1,482 @@
+package assistdog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rdumont/assistdog/defaults"
+)
+
+type address struct {
+	Street string
+	City   string
+}
+
+type customer struct {
+	Name     string
+	Address  address
+	Shipping *address
+}
+
+func TestNestedFields(t *testing.T) {
+	t.Run("creates nested fields", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Name", "John"},
+			{"Address.City", "Berlin"},
+			{"Shipping.Street", "Unter den Linden"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(customer), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*customer)
+		assert.Equal(t, "Berlin", typed.Address.City)
+		require.NotNil(t, typed.Shipping)
+		assert.Equal(t, "Unter den Linden", typed.Shipping.Street)
+	})
+
+	t.Run("creates slice with nested fields", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Name", "Shipping.City"},
+			{"John", "Berlin"},
+			{"Mary", "Paris"},
+		})
+
+		result, err := NewDefault().CreateSlice(new(customer), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.([]*customer)
+		require.Len(t, typed, 2)
+		assert.Equal(t, "Paris", typed[1].Shipping.City)
+	})
+
+	t.Run("reports unknown nested fields", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(customer), buildTable([][]string{
+			{"Address.Zip", "10117"},
+			{"Name.First", "John"},
+		}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.customer:
+- Address.Zip: field not found
+- Name.First: Name is not a struct`)
+	})
+
+	t.Run("compares nested fields", func(t *testing.T) {
+		actual := []*customer{
+			{Name: "John", Address: address{City: "Berlin"}, Shipping: &address{City: "Berlin"}},
+			{Name: "Mary", Address: address{City: "Rome"}},
+		}
+
+		err := NewDefault().CompareToSlice(actual, buildTable([][]string{
+			{"Name", "Address.City", "Shipping.City"},
+			{"John", "Berlin", "Berlin"},
+			{"Mary", "Paris", "Paris"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+row 1:
+  - Address.City: expected Paris, but got Rome
+  - Shipping.City: Shipping is nil`)
+	})
+}
+
+type phone struct {
+	Kind   string
+	Number string
+}
+
+type contact struct {
+	Name   string
+	Phones []phone
+	Emails [2]string
+	Backup []*phone
+}
+
+func TestIndexedFields(t *testing.T) {
+	t.Run("parses paths", func(t *testing.T) {
+		segments, err := parsePath("Items[2].Qty[0][1]")
+
+		require.NoError(t, err)
+		assert.Equal(t, []pathSegment{{name: "Items", indexes: []int{2}}, {name: "Qty", indexes: []int{0, 1}}}, segments)
+	})
+
+	t.Run("returns error for invalid index", func(t *testing.T) {
+		_, err := parsePath("Items[x].Qty")
+
+		require.EqualError(t, err, "invalid index x in Items[x]")
+	})
+
+	t.Run("returns error for empty segments", func(t *testing.T) {
+		_, err := parsePath("Phones[0].")
+
+		require.EqualError(t, err, `invalid header "Phones[0]."`)
+	})
+
+	t.Run("rejects empty headers", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(contact), buildTable([][]string{
+			{"", "John"},
+			{"Phones[0].", "555-0100"},
+		}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.contact:
+- : invalid header ""
+- Phones[0].: invalid header "Phones[0]."`)
+
+		_, ok := NewDefault().findStructField(reflect.TypeOf(contact{}), "")
+		assert.False(t, ok)
+	})
+
+	t.Run("creates slices of structs", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Name", "John"},
+			{"Phones[1].Number", "555-0101"},
+			{"Phones[0].Number", "555-0100"},
+			{"Phones[0].Kind", "home"},
+			{"Emails[1]", "john@example.com"},
+			{"Backup[0].Number", "555-0199"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(contact), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*contact)
+		assert.Equal(t, []phone{{Kind: "home", Number: "555-0100"}, {Number: "555-0101"}}, typed.Phones)
+		assert.Equal(t, [2]string{"", "john@example.com"}, typed.Emails)
+		require.Len(t, typed.Backup, 1)
+		assert.Equal(t, "555-0199", typed.Backup[0].Number)
+	})
+
+	t.Run("reports array index out of range when creating", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(contact), buildTable([][]string{{"Emails[2]", "x"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.contact:
+- Emails[2]: index 2 out of range for Emails (length 2)`)
+	})
+
+	t.Run("compares slice elements", func(t *testing.T) {
+		actual := &contact{Phones: []phone{{Kind: "home", Number: "555-0100"}, {Kind: "work", Number: "555-0101"}}}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"Phones[0].Number", "555-0100"},
+			{"Phones[1].Kind", "mobile"},
+			{"Phones[2].Number", "555-0102"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Phones[1].Kind: expected mobile, but got work
+- Phones[2].Number: index 2 out of range for Phones (length 2)`)
+	})
+}
+
+type employee struct {
+	FirstName string     `assist:"First Name,alias=fname,required"`
+	HiredOn   time.Time  `assist:"Hired On,format=02/01/2006"`
+	LeftOn    *time.Time `assist:"Left On,format=02/01/2006"`
+	Salary    int        `assist:",ignore"`
+	Team      string     `json:"team_name,omitempty"`
+	Secret    string     `json:"-"`
+}
+
+func TestStructTags(t *testing.T) {
+	t.Run("creates instance from tagged headers", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(employee), buildTable([][]string{
+			{"fname", "John"},
+			{"Hired On", "15/03/2020"},
+			{"Left On", "<nil>"},
+			{"team_name", "Platform"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*employee)
+		assert.Equal(t, "John", typed.FirstName)
+		assert.Equal(t, time.Date(2020, 3, 15, 0, 0, 0, 0, time.UTC), typed.HiredOn)
+		assert.Nil(t, typed.LeftOn)
+		assert.Equal(t, "Platform", typed.Team)
+	})
+
+	t.Run("still accepts field names", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(employee), buildTable([][]string{{"FirstName", "John"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "John", result.(*employee).FirstName)
+	})
+
+	t.Run("compares tagged fields", func(t *testing.T) {
+		left := time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC)
+		actual := &employee{FirstName: "John", HiredOn: time.Date(2020, 3, 15, 0, 0, 0, 0, time.UTC), LeftOn: &left, Secret: "x"}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"First Name", "John"},
+			{"Hired On", "15/03/2020"},
+			{"Left On", "01/07/2021"},
+			{"Secret", "y"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Left On: expected 2021-07-01 00:00:00 +0000 UTC, but got 2021-06-30 00:00:00 +0000 UTC
+- Secret: expected y, but got x`)
+	})
+
+	t.Run("reports headers of ignored fields", func(t *testing.T) {
+		table := buildTable([][]string{{"Salary", "1000"}})
+
+		_, err := NewDefault().CreateInstance(new(employee), table)
+		assert.EqualError(t, err, `failed to parse table as *assistdog.employee:
+- Salary: field is ignored
+- First Name: required field missing`)
+
+		err = NewDefault().CompareToInstance(&employee{Salary: 1000}, table)
+		assert.EqualError(t, err, `comparison failed:
+- Salary: field is ignored`)
+	})
+
+	t.Run("reports missing required fields", func(t *testing.T) {
+		_, err := NewDefault().CreateSlice(new(employee), buildTable([][]string{
+			{"team_name"},
+			{"Platform"},
+		}))
+
+		assert.EqualError(t, err, `failed to parse table as slice of *assistdog.employee:
+row 0:
+  - First Name: required field missing`)
+	})
+
+	t.Run("reports missing required fields of embedded and nested structs", func(t *testing.T) {
+		type contact struct {
+			Email string `assist:",required"`
+			Phone string
+		}
+
+		type account struct {
+			contact
+			Owner  contact
+			Backup *contact
+			Others []contact
+		}
+
+		_, err := NewDefault().CreateInstance(new(account), buildTable([][]string{
+			{"Phone", "555-0100"},
+			{"Owner.Phone", "555-0101"},
+			{"Backup.Email", "backup@example.com"},
+			{"Others[0].Phone", "555-0102"},
+			{"Others[1].Email", "other@example.com"},
+		}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.account:
+- Email: required field missing
+- Others[0].Email: required field missing
+- Owner.Email: required field missing`)
+	})
+
+	t.Run("accepts quoted values with commas", func(t *testing.T) {
+		type meeting struct {
+			At time.Time `assist:"At,format='Mon, 02 Jan 2006',alias=On"`
+		}
+
+		result, err := NewDefault().CreateInstance(new(meeting), buildTable([][]string{{"On", "Tue, 02 Mar 2021"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC), result.(*meeting).At)
+	})
+
+	t.Run("keeps quotes outside option values", func(t *testing.T) {
+		type pet struct {
+			Owner string `assist:"Owner's Name,alias=owner's"`
+		}
+
+		table := buildTable([][]string{{"Owner's Name", "John"}})
+		result, err := NewDefault().CreateInstance(new(pet), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "John", result.(*pet).Owner)
+		assert.NoError(t, NewDefault().CompareToInstance(&pet{Owner: "John"}, buildTable([][]string{{"owner's", "John"}})))
+	})
+
+	t.Run("reports unknown options of the fields in the table", func(t *testing.T) {
+		type invalid struct {
+			Name string `assist:"Name,requried"`
+			Age  int
+		}
+
+		result, err := NewDefault().CreateInstance(new(invalid), buildTable([][]string{{"Age", "30"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, 30, result.(*invalid).Age)
+
+		table := buildTable([][]string{{"Name", "John"}})
+		_, err = NewDefault().CreateInstance(new(invalid), table)
+		assert.EqualError(t, err, `failed to parse table as *assistdog.invalid:
+- Name: unknown tag option requried`)
+
+		err = NewDefault().CompareToInstance(&invalid{Name: "John"}, table)
+		assert.EqualError(t, err, `comparison failed:
+- Name: unknown tag option requried`)
+	})
+
+	t.Run("reports formats for other types", func(t *testing.T) {
+		type invalid struct {
+			Count int `assist:",format=000"`
+		}
+
+		_, err := NewDefault().CreateInstance(new(invalid), buildTable([][]string{{"Count", "1"}}))
+		assert.EqualError(t, err, `failed to parse table as *assistdog.invalid:
+- Count: format is not supported for int`)
+	})
+}
+
+func TestHeaderNormalization(t *testing.T) {
+	t.Run("matches headers regardless of case, spaces, underscores and hyphens", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(employee), buildTable([][]string{
+			{"first_name", "John"},
+			{"hired-on", "15/03/2020"},
+			{"TEAM NAME", "Platform"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*employee)
+		assert.Equal(t, "John", typed.FirstName)
+		assert.Equal(t, 2020, typed.HiredOn.Year())
+		assert.Equal(t, "Platform", typed.Team)
+	})
+
+	t.Run("uses the configured normalizer", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetHeaderNormalizer(strings.ToLower)
+
+		err := assist.CompareToInstance(&person{Name: "John"}, buildTable([][]string{
+			{"NAME", "John"},
+			{"na_me", "John"},
+		}))
+		assert.EqualError(t, err, `comparison failed:
+- na_me: field not found, did you mean Name?`)
+	})
+
+	t.Run("suggests the closest fields", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(&person{}, buildTable([][]string{
+			{"Heigth", "182"},
+			{"Nmae", "John"},
+			{"Age", "30"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Age: field not found
+- Heigth: field not found, did you mean Height?
+- Nmae: field not found, did you mean Name?`)
+	})
+
+	t.Run("suggests tagged headers", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(employee), buildTable([][]string{
+			{"First Nme", "John"},
+			{"Salary", "1000"},
+			{"Lef On", "<nil>"},
+		}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.employee:
+- First Nme: field not found, did you mean First Name?
+- Lef On: field not found, did you mean Left On?
+- Salary: field is ignored
+- First Name: required field missing`)
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("height", "height"))
+	assert.Equal(t, 1, editDistance("heigth", "height"))
+	assert.Equal(t, 2, editDistance("age", "name"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
+
+type member struct {
+	Email    string `assist:"E-mail"`
+	Code     string
+	Referrer *string
+}
+
+func TestFieldParsersAndComparers(t *testing.T) {
+	newAssist := func() *Assist {
+		assist := NewDefault()
+		assist.RegisterFieldParser(member{}, "E-mail", func(raw string) (interface{}, error) {
+			return strings.ToLower(raw), nil
+		})
+		assist.RegisterFieldComparer(new(member), "Email", defaults.StringComparer{Mode: defaults.StringFold}.Compare)
+		assist.RegisterFieldComparer(member{}, "Referrer", func(raw string, actual interface{}) error {
+			return defaults.StringComparer{Mode: defaults.StringFold}.Compare(raw, *actual.(*string))
+		})
+
+		return assist
+	}
+
+	t.Run("parses with the field's parser", func(t *testing.T) {
+		result, err := newAssist().CreateInstance(new(member), buildTable([][]string{
+			{"E-mail", "John@Example.com"},
+			{"Code", "AB-1"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*member)
+		assert.Equal(t, "john@example.com", typed.Email)
+		assert.Equal(t, "AB-1", typed.Code)
+	})
+
+	t.Run("compares with the field's comparer", func(t *testing.T) {
+		referrer := "Mary@Example.com"
+		actual := &member{Email: "john@example.com", Code: "ab-1", Referrer: &referrer}
+
+		err := newAssist().CompareToInstance(actual, buildTable([][]string{
+			{"Code", "AB-1"},
+			{"E-mail", "JOHN@example.com"},
+			{"Referrer", "mary@example.com"},
+		}))
+		assert.EqualError(t, err, `comparison failed:
+- Code: expected AB-1, but got ab-1`)
+
+		err = newAssist().CompareToInstance(&member{}, buildTable([][]string{
+			{"E-mail", "!= JOHN@example.com"},
+			{"Referrer", "<nil>"},
+		}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("falls back to the type after removal", func(t *testing.T) {
+		assist := newAssist()
+		assist.RemoveFieldComparer(member{}, "E-mail")
+
+		err := assist.CompareToInstance(&member{Email: "john@example.com"}, buildTable([][]string{{"Email", "JOHN@example.com"}}))
+		assert.EqualError(t, err, `comparison failed:
+- Email: expected JOHN@example.com, but got john@example.com`)
+	})
+
+	t.Run("panics for unknown fields", func(t *testing.T) {
+		assert.PanicsWithValue(t, "assistdog: assistdog.member has no field Phone", func() {
+			NewDefault().RegisterFieldParser(member{}, "Phone", defaults.ParseString)
+		})
+		assert.PanicsWithValue(t, "assistdog: expected a struct or a pointer to one, but got string", func() {
+			NewDefault().RegisterFieldComparer("", "Phone", defaults.CompareString)
+		})
+	})
+}
@@ -6,4 +6,5 @@ require (
 	github.com/cucumber/godog v0.10.0
 	github.com/cucumber/messages-go/v10 v10.0.3
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/text v0.3.6
 )
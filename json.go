@@ -0,0 +1,61 @@
+package assistdog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/rdumont/assistdog/defaults"
+)
+
+// EnableJSON makes cells that start with { or [ be decoded as JSON into fields of any type
+// other than strings, and compared to actual values by semantic JSON equality. Cells that can't
+// be decoded into the field's type are left to its usual parser. Fields of types that have no other
+// parser or comparer, as well as types implementing json.Unmarshaler and json.Marshaler,
+// are always handled as JSON.
+func (a *Assist) EnableJSON() {
+	a.RegisterInterfaceParser((*json.Unmarshaler)(nil), defaults.ParseJSON)
+	a.RegisterInterfaceComparer((*json.Marshaler)(nil), defaults.CompareJSON)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.jsonCells = true
+}
+
+// jsonCellParser decodes cells that look like JSON objects or arrays, leaving the others,
+// and those that can't be decoded into the type, to the parser resolved for the type.
+// It isn't used for string types, which hold JSON cells as they are.
+func jsonCellParser(tp reflect.Type, parse ParseFunc) ParseFunc {
+	return func(raw string) (interface{}, error) {
+		if isJSONCell(raw) {
+			if parsed, err := defaults.ParseJSON(tp, raw); err == nil {
+				return parsed, nil
+			}
+		}
+
+		return parse(raw)
+	}
+}
+
+// jsonCellComparer compares cells that look like JSON objects or arrays as JSON, leaving the
+// others to the comparer resolved for the type, which is also tried when the JSON doesn't match.
+// It isn't used for string types, which compare JSON cells as they are.
+func jsonCellComparer(compare CompareFunc) CompareFunc {
+	return func(raw string, actual interface{}) error {
+		if !isJSONCell(raw) {
+			return compare(raw, actual)
+		}
+
+		err := defaults.CompareJSON(raw, actual)
+		if err != nil && compare(raw, actual) == nil {
+			return nil
+		}
+
+		return err
+	}
+}
+
+func isJSONCell(raw string) bool {
+	trimmed := strings.TrimSpace(raw)
+	return (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed))
+}
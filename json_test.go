@@ -0,0 +1,132 @@
+package assistdog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type event struct {
+	Kind    string
+	Tags    []string
+	Payload map[string]interface{}
+	Origin  *address
+}
+
+func TestJSONCells(t *testing.T) {
+	t.Run("decodes JSON cells into any field type", func(t *testing.T) {
+		assist := NewDefault()
+		assist.EnableJSON()
+
+		result, err := assist.CreateInstance(new(event), buildTable([][]string{
+			{"Kind", "[draft]"},
+			{"Tags", `["a, b", "c"]`},
+			{"Payload", `{"id": 1, "items": [{"sku": "X"}]}`},
+			{"Origin", `{"City": "Berlin"}`},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*event)
+		assert.Equal(t, "[draft]", typed.Kind)
+		assert.Equal(t, []string{"a, b", "c"}, typed.Tags)
+		assert.Equal(t, map[string]interface{}{"id": 1.0, "items": []interface{}{map[string]interface{}{"sku": "X"}}}, typed.Payload)
+		require.NotNil(t, typed.Origin)
+		assert.Equal(t, "Berlin", typed.Origin.City)
+	})
+
+	t.Run("keeps null tokens for fields without other parsers", func(t *testing.T) {
+		assist := NewDefault()
+		assist.EnableJSON()
+
+		result, err := assist.CreateInstance(new(event), buildTable([][]string{{"Payload", "<nil>"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Nil(t, result.(*event).Payload)
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(event), buildTable([][]string{{"Payload", `{"id": 1}`}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.event:
+- Payload: unrecognized type map[string]interface {}`)
+	})
+
+	t.Run("compares JSON cells semantically", func(t *testing.T) {
+		assist := NewDefault()
+		assist.EnableJSON()
+		actual := &event{
+			Payload: map[string]interface{}{"id": 1, "items": []interface{}{map[string]interface{}{"sku": "X"}}},
+			Origin:  &address{City: "Berlin"},
+		}
+
+		err := assist.CompareToInstance(actual, buildTable([][]string{
+			{"Payload", `{"items": [{"sku": "Y"}], "id": 1}`},
+			{"Origin", `{"Street": "", "City": "Berlin"}`},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Payload: $.items[0].sku: expected "Y", but got "X"`)
+	})
+	t.Run("keeps JSON cells in string fields", func(t *testing.T) {
+		assist := NewDefault()
+		assist.EnableJSON()
+		table := buildTable([][]string{{"Kind", `{"a":1}`}})
+
+		result, err := assist.CreateInstance(new(event), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, `{"a":1}`, result.(*event).Kind)
+		assert.NoError(t, assist.CompareToInstance(result, table))
+		assert.EqualError(t, assist.CompareToInstance(&event{Kind: "[1]"}, buildTable([][]string{{"Kind", "[2]"}})), `comparison failed:
+- Kind: expected [2], but got [1]`)
+	})
+
+	t.Run("falls back to the type's parser and comparer", func(t *testing.T) {
+		assist := NewDefault()
+		assist.EnableJSON()
+		assist.RegisterParser(point{}, parsePoint)
+		assist.RegisterComparer(point{}, func(raw string, actual interface{}) error {
+			expected, err := parsePoint(raw)
+			if err != nil {
+				return err
+			}
+
+			if expected != actual {
+				return fmt.Errorf("expected %v, but got %v", expected, actual)
+			}
+
+			return nil
+		})
+		table := buildTable([][]string{{"Position", "[1, 2]"}})
+
+		result, err := assist.CreateInstance(new(marker), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, point{X: 1, Y: 2}, result.(*marker).Position)
+		assert.NoError(t, assist.CompareToInstance(result, table))
+	})
+}
+
+type point struct {
+	X, Y int
+}
+
+type marker struct {
+	Position point
+}
+
+func parsePoint(raw string) (interface{}, error) {
+	var p point
+	_, err := fmt.Sscanf(raw, "[%d, %d]", &p.X, &p.Y)
+	return p, err
+}
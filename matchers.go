@@ -0,0 +1,426 @@
+package assistdog
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MatchFunc checks whether an actual value matches, returning an error describing why it doesn't.
+type MatchFunc func(actual interface{}) error
+
+// Tokens of the matcher expressions described in CompareToInstance.
+const (
+	anyMatcher      = "<any>"
+	emptyMatcher    = "<empty>"
+	notEmptyMatcher = "<not empty>"
+)
+
+var (
+	regexpMatcher   = regexp.MustCompile(`^~/(.*)/$`)
+	orderMatcher    = regexp.MustCompile(`^(>=|<=|>|<)\s*(.+)$`)
+	notEqualMatcher = regexp.MustCompile(`^!=\s*(.*)$`)
+	betweenMatcher  = regexp.MustCompile(`(?i)^between\s+(.+?)\s+and\s+(.+)$`)
+	containsMatcher = regexp.MustCompile(`(?i)^contains\s+(.+)$`)
+	namedMatcher    = regexp.MustCompile(`^\{([\w.-]+)\}$`)
+	alternatives    = regexp.MustCompile(`\s+or\s+`)
+)
+
+// RegisterMatcher registers a named matcher, which can then be used in expected cells as {name}.
+// Matchers receive the actual value with non-nil pointers followed.
+// Names may contain letters, digits, underscores, dots and hyphens.
+// If a previous matcher already exists with the given name, it will be replaced.
+func (a *Assist) RegisterMatcher(name string, matcher MatchFunc) {
+	if !namedMatcher.MatchString("{" + name + "}") {
+		panic(fmt.Sprintf("assistdog: invalid matcher name %q", name))
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.matchers == nil {
+		a.matchers = map[string]MatchFunc{}
+	}
+
+	a.matchers[name] = matcher
+}
+
+// RemoveMatcher removes a named matcher.
+func (a *Assist) RemoveMatcher(name string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.matchers, name)
+}
+
+// Matchers returns the names of the registered matchers, sorted alphabetically.
+func (a *Assist) Matchers() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	names := make([]string, 0, len(a.matchers))
+	for name := range a.matchers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// matchNamed evaluates a matcher registered with RegisterMatcher against an actual value.
+func (a *Assist) matchNamed(name string, actual reflect.Value) error {
+	a.lock.RLock()
+	matcher, ok := a.matchers[name]
+	a.lock.RUnlock()
+	if !ok {
+		registered := "none"
+		if names := a.Matchers(); len(names) > 0 {
+			registered = "{" + strings.Join(names, "}, {") + "}"
+		}
+
+		return fmt.Errorf("unknown matcher {%v}, registered: %v", name, registered)
+	}
+
+	if err := matcher(describe(actual)); err != nil {
+		return fmt.Errorf("does not match {%v}: %v", name, err)
+	}
+
+	return nil
+}
+
+// compareValue compares an expected cell with an actual field value, evaluating matcher expressions
+// before falling back to the given comparer or, if it's nil, the comparer of the value's type.
+func (a *Assist) compareValue(raw string, actual reflect.Value, compare CompareFunc) error {
+	options := alternatives.Split(strings.TrimSpace(raw), -1)
+	if len(options) == 1 || !hasMatcher(options) {
+		return a.compareExpression(raw, actual, compare)
+	}
+
+	errs := []string{}
+	for _, option := range options {
+		err := a.compareExpression(option, actual, compare)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, err.Error())
+	}
+
+	return errors.New(strings.Join(errs, "; "))
+}
+
+func (a *Assist) compareExpression(raw string, actual reflect.Value, compare CompareFunc) error {
+	if matched, err := a.match(raw, actual, compare); matched {
+		return err
+	}
+
+	if compare == nil {
+		var ok bool
+		if compare, ok = a.findComparer(actual.Type()); !ok {
+			return fmt.Errorf("unrecognized type %v", actual.Type())
+		}
+	}
+
+	return compare(unescapeMatcher(raw), actual.Interface())
+}
+
+func hasMatcher(exprs []string) bool {
+	for _, expr := range exprs {
+		if isMatcher(expr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match evaluates a matcher expression against an actual field value, using the given comparer,
+// if any, instead of the comparer of the value's type. It reports whether the cell was a matcher
+// expression, in which case the returned error describes why it didn't match.
+func (a *Assist) match(raw string, actual reflect.Value, compare CompareFunc) (bool, error) {
+	expr := strings.TrimSpace(raw)
+	switch {
+	case expr == anyMatcher:
+		return true, nil
+	case expr == emptyMatcher:
+		if !isEmpty(actual) {
+			return true, fmt.Errorf("expected empty, but got %v", describe(actual))
+		}
+
+		return true, nil
+	case expr == notEmptyMatcher:
+		if isEmpty(actual) {
+			return true, fmt.Errorf("expected not empty, but got %v", describeEmpty(actual))
+		}
+
+		return true, nil
+	}
+
+	if m := namedMatcher.FindStringSubmatch(expr); m != nil {
+		return true, a.matchNamed(m[1], actual)
+	}
+
+	if m := regexpMatcher.FindStringSubmatch(expr); m != nil {
+		return true, matchRegexp(m[1], actual)
+	}
+
+	if m := betweenMatcher.FindStringSubmatch(expr); m != nil {
+		return true, a.matchBetween(m[1], m[2], actual)
+	}
+
+	if m := notEqualMatcher.FindStringSubmatch(expr); m != nil {
+		return true, a.matchNotEqual(m[1], actual, compare)
+	}
+
+	if m := orderMatcher.FindStringSubmatch(expr); m != nil && !isAngleToken(expr) {
+		return true, a.matchOrder(m[1], m[2], actual)
+	}
+
+	if m := containsMatcher.FindStringSubmatch(expr); m != nil {
+		return true, a.matchContains(m[1], actual)
+	}
+
+	return false, nil
+}
+
+// isMatcher reports whether a cell is a matcher expression.
+func isMatcher(raw string) bool {
+	expr := strings.TrimSpace(raw)
+	switch expr {
+	case anyMatcher, emptyMatcher, notEmptyMatcher:
+		return true
+	}
+
+	for _, re := range []*regexp.Regexp{namedMatcher, regexpMatcher, betweenMatcher, notEqualMatcher, containsMatcher} {
+		if re.MatchString(expr) {
+			return true
+		}
+	}
+
+	return orderMatcher.MatchString(expr) && !isAngleToken(expr)
+}
+
+// unescapeMatcher removes the backslash from cells that escape a matcher expression.
+func unescapeMatcher(raw string) string {
+	if strings.HasPrefix(raw, `\`) && isMatcher(raw[1:]) {
+		return raw[1:]
+	}
+
+	return raw
+}
+
+// isAngleToken reports whether a cell is a token such as <nil>, so that it isn't taken for an order matcher.
+func isAngleToken(expr string) bool {
+	return strings.HasPrefix(expr, "<") && strings.HasSuffix(expr, ">") && !strings.HasPrefix(expr, "<=")
+}
+
+func matchRegexp(pattern string, actual reflect.Value) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression /%v/: %v", pattern, err)
+	}
+
+	text := fmt.Sprint(describe(actual))
+	if !re.MatchString(text) {
+		return fmt.Errorf("expected to match /%v/, but got %v", pattern, text)
+	}
+
+	return nil
+}
+
+func (a *Assist) matchNotEqual(raw string, actual reflect.Value, compare CompareFunc) error {
+	if compare == nil {
+		var ok bool
+		if compare, ok = a.findComparer(actual.Type()); !ok {
+			return fmt.Errorf("unrecognized type %v", actual.Type())
+		}
+	}
+
+	if compare(raw, actual.Interface()) == nil {
+		return fmt.Errorf("expected anything but %v", raw)
+	}
+
+	return nil
+}
+
+func (a *Assist) matchOrder(operator, raw string, actual reflect.Value) error {
+	expected, err := a.parseForMatch(raw, actual.Type())
+	if err != nil {
+		return err
+	}
+
+	order, err := compareOrder(actual, expected)
+	if err != nil {
+		return err
+	}
+
+	matches := map[string]bool{
+		">":  order > 0,
+		">=": order >= 0,
+		"<":  order < 0,
+		"<=": order <= 0,
+	}[operator]
+	if !matches {
+		return fmt.Errorf("expected %v %v, but got %v", operator, raw, describe(actual))
+	}
+
+	return nil
+}
+
+func (a *Assist) matchBetween(rawLow, rawHigh string, actual reflect.Value) error {
+	low, err := a.parseForMatch(rawLow, actual.Type())
+	if err != nil {
+		return err
+	}
+
+	high, err := a.parseForMatch(rawHigh, actual.Type())
+	if err != nil {
+		return err
+	}
+
+	aboveLow, err := compareOrder(actual, low)
+	if err != nil {
+		return err
+	}
+
+	belowHigh, err := compareOrder(actual, high)
+	if err != nil {
+		return err
+	}
+
+	if aboveLow < 0 || belowHigh > 0 {
+		return fmt.Errorf("expected between %v and %v, but got %v", rawLow, rawHigh, describe(actual))
+	}
+
+	return nil
+}
+
+func (a *Assist) matchContains(raw string, actual reflect.Value) error {
+	v := actual
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		compare, ok := a.findComparer(v.Type().Elem())
+		if !ok {
+			return fmt.Errorf("unrecognized type %v", v.Type().Elem())
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			if compare(raw, v.Index(i).Interface()) == nil {
+				return nil
+			}
+		}
+	case reflect.Map:
+		key, err := a.parseForMatch(raw, v.Type().Key())
+		if err != nil {
+			return err
+		}
+
+		if v.MapIndex(key).IsValid() {
+			return nil
+		}
+	default:
+		if strings.Contains(fmt.Sprint(describe(v)), raw) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected to contain %v, but got %v", raw, describe(actual))
+}
+
+func (a *Assist) parseForMatch(raw string, tp reflect.Type) (reflect.Value, error) {
+	parse, ok := a.findParser(tp)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("unrecognized type %v", tp)
+	}
+
+	parsed, err := parse(raw)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	v := reflect.New(tp).Elem()
+	setValue(v, parsed)
+	return v, nil
+}
+
+// compareOrder returns a negative number if a is less than b, a positive number if a is greater
+// than b, and zero if they are equal. Pointers are followed, and only numbers, strings, times and
+// the math/big types can be ordered.
+func compareOrder(a, b reflect.Value) (int, error) {
+	switch av := a.Interface().(type) {
+	case *big.Int:
+		if bv, ok := b.Interface().(*big.Int); ok && av != nil && bv != nil {
+			return av.Cmp(bv), nil
+		}
+	case *big.Float:
+		if bv, ok := b.Interface().(*big.Float); ok && av != nil && bv != nil {
+			return av.Cmp(bv), nil
+		}
+	case *big.Rat:
+		if bv, ok := b.Interface().(*big.Rat); ok && av != nil && bv != nil {
+			return av.Cmp(bv), nil
+		}
+	}
+
+	for a.Kind() == reflect.Ptr && b.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return 0, fmt.Errorf("cannot order nil values")
+		}
+
+		a, b = a.Elem(), b.Elem()
+	}
+
+	if at, ok := a.Interface().(time.Time); ok {
+		bt := b.Interface().(time.Time)
+		return orderOf(at.Before(bt), at.After(bt)), nil
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return orderOf(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return orderOf(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return orderOf(a.Float() < b.Float(), a.Float() > b.Float()), nil
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	}
+
+	return 0, fmt.Errorf("values of type %v cannot be ordered", a.Type())
+}
+
+func orderOf(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func describeEmpty(v reflect.Value) interface{} {
+	if v.Kind() == reflect.String && v.Len() == 0 {
+		return `""`
+	}
+
+	return describe(v)
+}
@@ -0,0 +1,203 @@
+package assistdog
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type purchase struct {
+	ID       string
+	Quantity int
+	Price    float64
+	Notes    *string
+	Tags     []string
+	Stock    map[string]int
+	Placed   time.Time
+	Status   shipmentStatus
+	Internal chan int
+}
+
+func TestMatchers(t *testing.T) {
+	notes := "leave at the door"
+	actual := &purchase{
+		ID:       "ORD-1234",
+		Quantity: 3,
+		Price:    9.5,
+		Notes:    &notes,
+		Tags:     []string{"gift", "express"},
+		Stock:    map[string]int{"warehouse": 10},
+		Placed:   time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+		Status:   shipped,
+		Internal: make(chan int),
+	}
+
+	t.Run("matches successfully", func(t *testing.T) {
+		err := newEnumAssist().CompareToInstance(actual, buildTable([][]string{
+			{"ID", `~/^ORD-\d+$/`},
+			{"Quantity", "between 1 and 5"},
+			{"Price", "< 10"},
+			{"Notes", "contains door"},
+			{"Tags", "contains express"},
+			{"Stock", "contains warehouse"},
+			{"Placed", ">= 2020-01-01"},
+			{"Status", "!= Pending"},
+			{"Internal", "<not empty>"},
+		}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("matches types without a comparer", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Internal", "<any>"}}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports mismatches", func(t *testing.T) {
+		err := newEnumAssist().CompareToInstance(actual, buildTable([][]string{
+			{"ID", `~/^INV-\d+$/`},
+			{"Quantity", "> 10"},
+			{"Price", "between 1 and 5"},
+			{"Notes", "<empty>"},
+			{"Tags", "contains sale"},
+			{"Status", "!= shipped"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- ID: expected to match /^INV-\d+$/, but got ORD-1234
+- Notes: expected empty, but got leave at the door
+- Price: expected between 1 and 5, but got 9.5
+- Quantity: expected > 10, but got 3
+- Status: expected anything but shipped
+- Tags: expected to contain sale, but got [gift express]`)
+	})
+
+	t.Run("treats empty values as empty", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(&purchase{}, buildTable([][]string{
+			{"ID", "<empty>"},
+			{"Notes", "<empty>"},
+			{"Tags", "<empty>"},
+			{"Quantity", "<not empty>"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Quantity: expected not empty, but got 0`)
+	})
+
+	t.Run("keeps null tokens for the comparer", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(&purchase{}, buildTable([][]string{{"Notes", "<nil>"}}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("compares escaped matchers literally", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(&purchase{ID: "<any>"}, buildTable([][]string{{"ID", `\<any>`}}))
+		assert.NoError(t, err)
+
+		err = NewDefault().CompareToInstance(&purchase{ID: "other"}, buildTable([][]string{{"ID", `\<any>`}}))
+		assert.EqualError(t, err, `comparison failed:
+- ID: expected <any>, but got other`)
+	})
+
+	t.Run("reports unparsable bounds", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{{"Tags", "> 3"}}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Tags: values of type []string cannot be ordered`)
+	})
+}
+
+func isUUID(actual interface{}) error {
+	s, ok := actual.(string)
+	if !ok || !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`).MatchString(s) {
+		return fmt.Errorf("%v is not a UUID", actual)
+	}
+
+	return nil
+}
+
+type ticket struct {
+	ID     string
+	Parent *string
+	Title  string
+}
+
+func TestNamedMatchers(t *testing.T) {
+	newAssist := func() *Assist {
+		assist := NewDefault()
+		assist.RegisterMatcher("uuid", isUUID)
+		assist.RegisterMatcher("iso-country", func(actual interface{}) error {
+			if len(fmt.Sprint(actual)) != 2 {
+				return fmt.Errorf("%v is not a country code", actual)
+			}
+
+			return nil
+		})
+
+		return assist
+	}
+
+	t.Run("lists registered matchers", func(t *testing.T) {
+		assist := newAssist()
+		assert.Equal(t, []string{"iso-country", "uuid"}, assist.Matchers())
+
+		assist.RemoveMatcher("iso-country")
+		assert.Equal(t, []string{"uuid"}, assist.Matchers())
+	})
+
+	t.Run("matches placeholders", func(t *testing.T) {
+		err := newAssist().CompareToInstance(&ticket{ID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427"}, buildTable([][]string{
+			{"ID", "{uuid}"},
+			{"Parent", "{uuid} or <nil>"},
+		}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("names the failing matcher", func(t *testing.T) {
+		parent := "none"
+		err := newAssist().CompareToInstance(&ticket{ID: "42", Parent: &parent}, buildTable([][]string{
+			{"ID", "{uuid}"},
+			{"Parent", "{uuid} or <nil>"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- ID: does not match {uuid}: 42 is not a UUID
+- Parent: does not match {uuid}: none is not a UUID; expected nil, but got none`)
+	})
+
+	t.Run("combines built-in matchers", func(t *testing.T) {
+		err := newAssist().CompareToInstance(&ticket{Title: "n/a"}, buildTable([][]string{{"Title", "<empty> or n/a"}}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports unknown matchers", func(t *testing.T) {
+		err := newAssist().CompareToInstance(&ticket{ID: "42"}, buildTable([][]string{{"ID", "{uuidd}"}}))
+		assert.EqualError(t, err, `comparison failed:
+- ID: unknown matcher {uuidd}, registered: {iso-country}, {uuid}`)
+
+		err = NewDefault().CompareToInstance(&ticket{ID: "42"}, buildTable([][]string{{"ID", "{uuid}"}}))
+		assert.EqualError(t, err, `comparison failed:
+- ID: unknown matcher {uuid}, registered: none`)
+	})
+
+	t.Run("compares escaped placeholders literally", func(t *testing.T) {
+		assist := newAssist()
+		err := assist.CompareToInstance(&ticket{Title: "{draft}"}, buildTable([][]string{{"Title", `\{draft}`}}))
+		assert.NoError(t, err)
+
+		err = assist.CompareToInstance(&ticket{Title: "{uuid}"}, buildTable([][]string{{"Title", `\{uuid}`}}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("panics for invalid names", func(t *testing.T) {
+		assert.PanicsWithValue(t, `assistdog: invalid matcher name "a b"`, func() {
+			NewDefault().RegisterMatcher("a b", isUUID)
+		})
+	})
+}
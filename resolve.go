@@ -0,0 +1,400 @@
+package assistdog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rdumont/assistdog/defaults"
+)
+
+// kindTypes maps each basic kind to its predeclared type, so that named types
+// such as `type Status string` can fall back to the parser of their underlying type.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+type interfaceParser struct {
+	iface reflect.Type
+	parse InterfaceParseFunc
+}
+
+type interfaceComparer struct {
+	iface   reflect.Type
+	compare CompareFunc
+}
+
+func (a *Assist) findParser(tp reflect.Type) (ParseFunc, bool) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	p, ok := a.resolveParser(tp)
+	if ok && a.jsonCells && tp.Kind() != reflect.String {
+		p = jsonCellParser(tp, p)
+	}
+
+	return p, ok
+}
+
+func (a *Assist) findComparer(tp reflect.Type) (CompareFunc, bool) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	c, ok := a.resolveComparer(tp)
+	if ok && a.jsonCells && tp.Kind() != reflect.String {
+		c = jsonCellComparer(c)
+	}
+
+	return c, ok
+}
+
+// resolveParser looks for a parser for the given type, trying in order:
+// a parser registered for the exact type, the parser of the element of a pointer type,
+// the database/sql null types and scanners, a parser registered for an interface the type
+// implements, the parsers of the elements of a slice, array or map type, a parser registered
+// for the type's underlying kind and, if enabled, a JSON decoder. Types that can be nil also
+// accept the null tokens. It must be called with the lock held.
+func (a *Assist) resolveParser(tp reflect.Type) (ParseFunc, bool) {
+	p, ok := a.lookupParser(tp)
+	if !ok {
+		return nil, false
+	}
+
+	return nullableParser(tp, p, a.currentNullTokens()), true
+}
+
+// resolveComparer looks for a comparer for the given type, following the same order as resolveParser.
+// It must be called with the lock held.
+func (a *Assist) resolveComparer(tp reflect.Type) (CompareFunc, bool) {
+	c, ok := a.lookupComparer(tp)
+	if !ok {
+		return nil, false
+	}
+
+	return nullableComparer(tp, c, a.currentNullTokens()), true
+}
+
+// valueParser adapts a parser that returns pointers into one that returns the values they point to.
+func valueParser(parse ParseFunc) ParseFunc {
+	return func(raw string) (interface{}, error) {
+		parsed, err := parse(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.ValueOf(parsed).Elem().Interface(), nil
+	}
+}
+
+// valueComparer adapts a comparer of pointers into one that compares the values they point to.
+func valueComparer(compare CompareFunc) CompareFunc {
+	return func(raw string, actual interface{}) error {
+		ptr := reflect.New(reflect.TypeOf(actual))
+		ptr.Elem().Set(reflect.ValueOf(actual))
+		return compare(raw, ptr.Interface())
+	}
+}
+
+// nullableParser makes a parser for a type that can be nil accept the null tokens.
+func nullableParser(tp reflect.Type, p ParseFunc, tokens []string) ParseFunc {
+	if !isNilable(tp) {
+		return p
+	}
+
+	return func(raw string) (interface{}, error) {
+		if isNullToken(raw, tokens) {
+			return reflect.Zero(tp).Interface(), nil
+		}
+
+		return p(raw)
+	}
+}
+
+// nullableComparer makes a comparer for a type that can be nil accept the null tokens.
+func nullableComparer(tp reflect.Type, c CompareFunc, tokens []string) CompareFunc {
+	if !isNilable(tp) {
+		return c
+	}
+
+	return func(raw string, actual interface{}) error {
+		av := reflect.ValueOf(actual)
+		isNil := !av.IsValid() || av.IsNil()
+		if isNullToken(raw, tokens) {
+			if !isNil {
+				return fmt.Errorf("expected nil, but got %v", describe(av))
+			}
+
+			return nil
+		}
+
+		// Nil slices and maps are compared like empty ones, as empty cells create them.
+		if isNil && tp.Kind() != reflect.Slice && tp.Kind() != reflect.Map {
+			return fmt.Errorf("expected %v, but got nil", raw)
+		}
+
+		return c(raw, actual)
+	}
+}
+
+// pointerParser makes a parser for a pointer type out of the parser of its element.
+func pointerParser(tp reflect.Type, elemParse ParseFunc) ParseFunc {
+	return func(raw string) (interface{}, error) {
+		parsed, err := elemParse(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		ptr := reflect.New(tp.Elem())
+		setValue(ptr.Elem(), parsed)
+		return ptr.Interface(), nil
+	}
+}
+
+// pointerComparer makes a comparer for a pointer type out of the comparer of its element.
+func pointerComparer(elemCompare CompareFunc) CompareFunc {
+	return func(raw string, actual interface{}) error {
+		return elemCompare(raw, reflect.ValueOf(actual).Elem().Interface())
+	}
+}
+
+func (a *Assist) lookupParser(tp reflect.Type) (ParseFunc, bool) {
+	if p, ok := a.parsers[tp]; ok {
+		return p, true
+	}
+
+	if tp.Kind() == reflect.Ptr {
+		if elemParse, ok := a.resolveParser(tp.Elem()); ok {
+			return pointerParser(tp, elemParse), true
+		}
+	}
+
+	if p, ok := a.lookupSQLParser(tp); ok {
+		return p, true
+	}
+
+	for _, ip := range a.interfaceParsers {
+		if implements(tp, ip.iface) {
+			parse := ip.parse
+			return func(raw string) (interface{}, error) {
+				return parse(tp, raw)
+			}, true
+		}
+	}
+
+	if p, ok := a.lookupListParser(tp); ok {
+		return p, true
+	}
+
+	if p, ok := a.lookupMapParser(tp); ok {
+		return p, true
+	}
+
+	if base, ok := kindTypes[tp.Kind()]; ok && base != tp {
+		if p, ok := a.parsers[base]; ok {
+			return func(raw string) (interface{}, error) {
+				parsed, err := p(raw)
+				if err != nil {
+					return nil, err
+				}
+
+				return convert(parsed, tp)
+			}, true
+		}
+	}
+
+	if a.jsonCells {
+		return func(raw string) (interface{}, error) {
+			return defaults.ParseJSON(tp, raw)
+		}, true
+	}
+
+	return nil, false
+}
+
+func (a *Assist) lookupComparer(tp reflect.Type) (CompareFunc, bool) {
+	if c, ok := a.comparers[tp]; ok {
+		return c, true
+	}
+
+	if tp.Kind() == reflect.Ptr {
+		if elemCompare, ok := a.resolveComparer(tp.Elem()); ok {
+			return pointerComparer(elemCompare), true
+		}
+	}
+
+	if c, ok := a.lookupSQLComparer(tp); ok {
+		return c, true
+	}
+
+	for _, ic := range a.interfaceComparers {
+		if !implements(tp, ic.iface) {
+			continue
+		}
+
+		compare := ic.compare
+		if !tp.Implements(ic.iface) {
+			valueCompare := compare
+			compare = func(raw string, actual interface{}) error {
+				ptr := reflect.New(tp)
+				ptr.Elem().Set(reflect.ValueOf(actual))
+				return valueCompare(raw, ptr.Interface())
+			}
+		}
+
+		if ic.iface == stringerType {
+			if c, ok := a.lookupKindComparer(tp); ok {
+				return stringerKindComparer(a.parsers[kindTypes[tp.Kind()]], c, compare), true
+			}
+		}
+
+		return compare, true
+	}
+
+	if c, ok := a.lookupListComparer(tp); ok {
+		return c, true
+	}
+
+	if c, ok := a.lookupMapComparer(tp); ok {
+		return c, true
+	}
+
+	if c, ok := a.lookupKindComparer(tp); ok {
+		return c, true
+	}
+
+	if a.jsonCells {
+		return defaults.CompareJSON, true
+	}
+
+	return nil, false
+}
+
+// lookupKindComparer looks for a comparer registered for the underlying kind of a named type.
+func (a *Assist) lookupKindComparer(tp reflect.Type) (CompareFunc, bool) {
+	base, ok := kindTypes[tp.Kind()]
+	if !ok || base == tp {
+		return nil, false
+	}
+
+	c, ok := a.comparers[base]
+	if !ok {
+		return nil, false
+	}
+
+	return func(raw string, actual interface{}) error {
+		converted, err := convert(actual, base)
+		if err != nil {
+			return err
+		}
+
+		return c(raw, converted)
+	}, true
+}
+
+// stringerKindComparer compares named types of a basic kind that implement fmt.Stringer by their kind
+// when the cell can be parsed as it, as CreateInstance would, and by their text otherwise.
+// This way, `type celsius float64` matches both 21.55 and "21.6°C".
+func stringerKindComparer(parseKind ParseFunc, compareKind, compareText CompareFunc) CompareFunc {
+	return func(raw string, actual interface{}) error {
+		if parseKind == nil {
+			if compareKind(raw, actual) == nil {
+				return nil
+			}
+
+			return compareText(raw, actual)
+		}
+
+		if _, err := parseKind(raw); err == nil {
+			return compareKind(raw, actual)
+		}
+
+		return compareText(raw, actual)
+	}
+}
+
+func (a *Assist) currentNullTokens() []string {
+	if a.nullTokens == nil {
+		return DefaultNullTokens
+	}
+
+	return a.nullTokens
+}
+
+func isNullToken(raw string, tokens []string) bool {
+	trimmed := strings.TrimSpace(raw)
+	for _, token := range tokens {
+		if strings.EqualFold(token, trimmed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNilable(tp reflect.Type) bool {
+	switch tp.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// describe formats a value for error messages, following pointers to the value they point to.
+func describe(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// setValue sets a parsed value into a settable reflect value, treating untyped nil as the zero value.
+func setValue(v reflect.Value, parsed interface{}) {
+	if parsed == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+
+	v.Set(reflect.ValueOf(parsed))
+}
+
+// implements reports whether a type or a pointer to it implements an interface.
+func implements(tp, iface reflect.Type) bool {
+	return tp.Implements(iface) || (tp.Kind() != reflect.Ptr && reflect.PtrTo(tp).Implements(iface))
+}
+
+func convert(value interface{}, tp reflect.Type) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !v.Type().ConvertibleTo(tp) {
+		return nil, fmt.Errorf("%v cannot be converted to %v", value, tp)
+	}
+
+	return v.Convert(tp).Interface(), nil
+}
+
+func interfaceType(iface interface{}) reflect.Type {
+	tp := reflect.TypeOf(iface)
+	if tp == nil || tp.Kind() != reflect.Ptr || tp.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("assistdog: expected a nil pointer to an interface, but got %v", tp))
+	}
+
+	return tp.Elem()
+}
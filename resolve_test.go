@@ -0,0 +1,350 @@
+package assistdog
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type status string
+
+type userID int
+
+type order struct {
+	ID     userID
+	Status status
+	Code   upperCode
+}
+
+type shouter interface {
+	Shout() string
+}
+
+type upperCode struct {
+	value string
+}
+
+func (c *upperCode) Shout() string {
+	return strings.ToUpper(c.value)
+}
+
+func parseShouter(tp reflect.Type, raw string) (interface{}, error) {
+	ptr := reflect.New(tp)
+	ptr.Elem().Set(reflect.ValueOf(upperCode{value: raw}))
+	return ptr.Elem().Interface(), nil
+}
+
+func compareShouter(raw string, actual interface{}) error {
+	shouted := actual.(shouter).Shout()
+	if shouted != raw {
+		return fmt.Errorf("expected %v, but got %v", raw, shouted)
+	}
+
+	return nil
+}
+
+func TestKindFallback(t *testing.T) {
+	t.Run("creates named types from their underlying kind", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"ID", "42"},
+			{"Status", "Shipped"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(order), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &order{ID: 42, Status: "Shipped"}, result)
+	})
+
+	t.Run("compares named types with the comparer of their underlying kind", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"ID", "42"},
+			{"Status", "Pending"},
+		})
+
+		err := NewDefault().CompareToInstance(&order{ID: 42, Status: "Shipped"}, table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `comparison failed:
+- Status: expected Pending, but got Shipped`, err.Error())
+	})
+
+	t.Run("prefers exact type over kind", func(t *testing.T) {
+		assist := NewDefault()
+		assist.RegisterParser(status(""), func(raw string) (interface{}, error) {
+			return status(strings.ToLower(raw)), nil
+		})
+
+		result, err := assist.CreateInstance(new(order), buildTable([][]string{{"Status", "Shipped"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, status("shipped"), result.(*order).Status)
+	})
+}
+
+func TestInterfaceFallback(t *testing.T) {
+	t.Run("creates and compares types implementing an interface with pointer receivers", func(t *testing.T) {
+		assist := NewDefault()
+		assist.RegisterInterfaceParser((*shouter)(nil), parseShouter)
+		assist.RegisterInterfaceComparer((*shouter)(nil), compareShouter)
+
+		result, err := assist.CreateInstance(new(order), buildTable([][]string{{"Code", "abc"}}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, upperCode{value: "abc"}, result.(*order).Code)
+
+		err = assist.CompareToInstance(result, buildTable([][]string{{"Code", "abc"}}))
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `comparison failed:
+- Code: expected abc, but got ABC`, err.Error())
+	})
+
+	t.Run("removes interface parsers and comparers", func(t *testing.T) {
+		assist := new(Assist)
+		assist.RegisterInterfaceParser((*shouter)(nil), parseShouter)
+		assist.RegisterInterfaceComparer((*shouter)(nil), compareShouter)
+
+		assist.RemoveInterfaceParser((*shouter)(nil))
+		assist.RemoveInterfaceComparer((*shouter)(nil))
+
+		assert.Len(t, assist.interfaceParsers, 0)
+		assert.Len(t, assist.interfaceComparers, 0)
+	})
+
+	t.Run("panics for types that are not interfaces", func(t *testing.T) {
+		require.PanicsWithValue(t, "assistdog: expected a nil pointer to an interface, but got string", func() {
+			NewDefault().RegisterInterfaceParser("", parseShouter)
+		})
+	})
+}
+
+type optionals struct {
+	Nickname *string
+	Age      *int
+	Born     *time.Time
+	Status   *status
+}
+
+func TestPointers(t *testing.T) {
+	t.Run("creates pointer fields", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Nickname", "Johnny"},
+			{"Age", "42"},
+			{"Born", "2020-11-05T16:01:54Z"},
+			{"Status", "<nil>"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(optionals), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*optionals)
+		require.NotNil(t, typed.Nickname)
+		assert.Equal(t, "Johnny", *typed.Nickname)
+		require.NotNil(t, typed.Age)
+		assert.Equal(t, 42, *typed.Age)
+		require.NotNil(t, typed.Born)
+		assert.Equal(t, 2020, typed.Born.Year())
+		assert.Nil(t, typed.Status)
+	})
+
+	t.Run("compares pointer fields", func(t *testing.T) {
+		age := 42
+		table := buildTable([][]string{
+			{"Age", "42"},
+			{"Nickname", "<nil>"},
+		})
+
+		err := NewDefault().CompareToInstance(&optionals{Age: &age}, table)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports value where nil was expected", func(t *testing.T) {
+		age := 42
+		table := buildTable([][]string{
+			{"Age", "<nil>"},
+		})
+
+		err := NewDefault().CompareToInstance(&optionals{Age: &age}, table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `comparison failed:
+- Age: expected nil, but got 42`, err.Error())
+	})
+
+	t.Run("reports nil where a value was expected", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Age", "42"},
+		})
+
+		err := NewDefault().CompareToInstance(&optionals{}, table)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, `comparison failed:
+- Age: expected 42, but got nil`, err.Error())
+	})
+
+	t.Run("uses custom null tokens", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetNullTokens("null", "-")
+
+		result, err := assist.CreateInstance(new(optionals), buildTable([][]string{
+			{"Nickname", "NULL"},
+			{"Age", "-"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &optionals{}, result)
+
+		_, err = assist.CreateInstance(new(optionals), buildTable([][]string{{"Age", "<nil>"}}))
+		assert.EqualError(t, err, `failed to parse table as *assistdog.optionals:
+- Age: strconv.Atoi: parsing "<nil>": invalid syntax`)
+	})
+}
+
+type cents int64
+
+func (c cents) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", c/100, c%100)), nil
+}
+
+func (c *cents) UnmarshalText(text []byte) error {
+	var units, fraction int64
+	if _, err := fmt.Sscanf(string(text), "%d.%d", &units, &fraction); err != nil {
+		return fmt.Errorf("invalid amount %s", text)
+	}
+
+	*c = cents(units*100 + fraction)
+	return nil
+}
+
+type tier struct {
+	name string
+}
+
+func (t *tier) String() string {
+	return strings.ToUpper(t.name)
+}
+
+type celsius float64
+
+func (c celsius) String() string {
+	return strconv.FormatFloat(float64(c), 'f', 1, 64) + "°C"
+}
+
+type forecast struct {
+	High celsius
+}
+
+func TestStringerKindTypes(t *testing.T) {
+	t.Run("compares created values by kind", func(t *testing.T) {
+		table := buildTable([][]string{{"High", "21.55"}})
+		assist := NewDefault()
+
+		result, err := assist.CreateInstance(new(forecast), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NoError(t, assist.CompareToInstance(result, table))
+	})
+
+	t.Run("uses the configured epsilon", func(t *testing.T) {
+		assist := NewDefault()
+		assist.SetFloatEpsilon(0.1)
+
+		assert.NoError(t, assist.CompareToInstance(&forecast{High: 21.55}, buildTable([][]string{{"High", "21.6"}})))
+	})
+
+	t.Run("compares other cells by text", func(t *testing.T) {
+		actual := &forecast{High: 21.55}
+
+		assert.NoError(t, NewDefault().CompareToInstance(actual, buildTable([][]string{{"High", "21.6°C"}})))
+		assert.EqualError(t, NewDefault().CompareToInstance(actual, buildTable([][]string{{"High", "20.0°C"}})), `comparison failed:
+- High: expected 20.0°C, but got 21.6°C`)
+		assert.EqualError(t, NewDefault().CompareToInstance(actual, buildTable([][]string{{"High", "20"}})), `comparison failed:
+- High: expected 20, but got 21.55`)
+	})
+}
+
+type invoice struct {
+	Total    cents
+	Discount *cents
+	Tier     tier
+	Month    time.Month
+}
+
+func TestTextInterfaces(t *testing.T) {
+	t.Run("creates fields through encoding.TextUnmarshaler", func(t *testing.T) {
+		table := buildTable([][]string{
+			{"Total", "12.50"},
+			{"Discount", "1.05"},
+		})
+
+		result, err := NewDefault().CreateInstance(new(invoice), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		typed := result.(*invoice)
+		assert.Equal(t, cents(1250), typed.Total)
+		require.NotNil(t, typed.Discount)
+		assert.Equal(t, cents(105), *typed.Discount)
+	})
+
+	t.Run("reports unmarshaling errors", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(invoice), buildTable([][]string{{"Total", "lots"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.invoice:
+- Total: invalid amount lots`)
+	})
+
+	t.Run("compares fields through encoding.TextMarshaler", func(t *testing.T) {
+		discount := cents(105)
+		actual := &invoice{Total: 1250, Discount: &discount}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"Total", "0012.50"},
+			{"Discount", "1.06"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Discount: expected 1.06, but got 1.05`)
+	})
+
+	t.Run("compares fields through fmt.Stringer with pointer receivers", func(t *testing.T) {
+		actual := &invoice{Tier: tier{name: "gold"}, Month: time.March}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"Tier", "GOLD"},
+			{"Month", "April"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Month: expected April, but got March`)
+	})
+}
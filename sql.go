@@ -0,0 +1,171 @@
+package assistdog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// sqlNullTypes are the database/sql types that wrap a value along with a Valid flag.
+// The wrapped value is always their first field.
+var sqlNullTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sql.NullString{}):  true,
+	reflect.TypeOf(sql.NullInt64{}):   true,
+	reflect.TypeOf(sql.NullInt32{}):   true,
+	reflect.TypeOf(sql.NullFloat64{}): true,
+	reflect.TypeOf(sql.NullBool{}):    true,
+	reflect.TypeOf(sql.NullTime{}):    true,
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// lookupSQLParser returns a parser for the database/sql null types, which parses the wrapped
+// value with the parser of its type, and for any other type implementing sql.Scanner, which
+// scans the raw string. In both cases, the null tokens produce an invalid, or NULL, value.
+// It must be called with the lock held.
+func (a *Assist) lookupSQLParser(tp reflect.Type) (ParseFunc, bool) {
+	tokens := a.currentNullTokens()
+	if sqlNullTypes[tp] {
+		valueParse, ok := a.resolveParser(tp.Field(0).Type)
+		if !ok {
+			return nil, false
+		}
+
+		return func(raw string) (interface{}, error) {
+			result := reflect.New(tp).Elem()
+			if isNullToken(raw, tokens) {
+				return result.Interface(), nil
+			}
+
+			parsed, err := valueParse(raw)
+			if err != nil {
+				return nil, err
+			}
+
+			setValue(result.Field(0), parsed)
+			result.FieldByName("Valid").SetBool(true)
+			return result.Interface(), nil
+		}, true
+	}
+
+	if tp.Kind() == reflect.Ptr || !reflect.PtrTo(tp).Implements(scannerType) {
+		return nil, false
+	}
+
+	return func(raw string) (interface{}, error) {
+		ptr := reflect.New(tp)
+		var src interface{} = raw
+		if isNullToken(raw, tokens) {
+			src = nil
+		}
+
+		if err := ptr.Interface().(sql.Scanner).Scan(src); err != nil {
+			return nil, err
+		}
+
+		return ptr.Elem().Interface(), nil
+	}, true
+}
+
+// lookupSQLComparer returns a comparer for the database/sql null types, which compares the
+// wrapped value with the comparer of its type, and for any other type implementing driver.Valuer,
+// which compares the driver values. In both cases, the null tokens expect an invalid, or NULL, value.
+// It must be called with the lock held.
+func (a *Assist) lookupSQLComparer(tp reflect.Type) (CompareFunc, bool) {
+	tokens := a.currentNullTokens()
+	if sqlNullTypes[tp] {
+		valueCompare, ok := a.resolveComparer(tp.Field(0).Type)
+		if !ok {
+			return nil, false
+		}
+
+		return func(raw string, actual interface{}) error {
+			av := reflect.ValueOf(actual)
+			valid := av.FieldByName("Valid").Bool()
+			if isNullToken(raw, tokens) {
+				if valid {
+					return fmt.Errorf("expected nil, but got %v", describe(av.Field(0)))
+				}
+
+				return nil
+			}
+
+			if !valid {
+				return fmt.Errorf("expected %v, but got nil", raw)
+			}
+
+			return valueCompare(raw, av.Field(0).Interface())
+		}, true
+	}
+
+	if tp.Kind() == reflect.Ptr || !implements(tp, valuerType) {
+		return nil, false
+	}
+
+	scannable := reflect.PtrTo(tp).Implements(scannerType)
+	return func(raw string, actual interface{}) error {
+		actualValue, err := driverValue(tp, actual)
+		if err != nil {
+			return err
+		}
+
+		if isNullToken(raw, tokens) {
+			if actualValue != nil {
+				return fmt.Errorf("expected nil, but got %v", formatDriverValue(actualValue))
+			}
+
+			return nil
+		}
+
+		if actualValue == nil {
+			return fmt.Errorf("expected %v, but got nil", raw)
+		}
+
+		if !scannable {
+			if formatted := formatDriverValue(actualValue); formatted != raw {
+				return fmt.Errorf("expected %v, but got %v", raw, formatted)
+			}
+
+			return nil
+		}
+
+		ptr := reflect.New(tp)
+		if err := ptr.Interface().(sql.Scanner).Scan(raw); err != nil {
+			return err
+		}
+
+		expectedValue, err := driverValue(tp, ptr.Elem().Interface())
+		if err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(expectedValue, actualValue) {
+			return fmt.Errorf("expected %v, but got %v", formatDriverValue(expectedValue), formatDriverValue(actualValue))
+		}
+
+		return nil
+	}, true
+}
+
+// driverValue returns the driver value of a driver.Valuer, even if only a pointer to its type implements it.
+func driverValue(tp reflect.Type, value interface{}) (driver.Value, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	ptr := reflect.New(tp)
+	ptr.Elem().Set(reflect.ValueOf(value))
+	return ptr.Interface().(driver.Valuer).Value()
+}
+
+func formatDriverValue(value driver.Value) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+
+	return fmt.Sprint(value)
+}
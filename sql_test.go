@@ -0,0 +1,101 @@
+package assistdog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sku struct {
+	code  string
+	valid bool
+}
+
+func (s *sku) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = sku{}
+	case string:
+		*s = sku{code: strings.ToUpper(v), valid: true}
+	default:
+		return fmt.Errorf("cannot scan %T into sku", src)
+	}
+
+	return nil
+}
+
+func (s sku) Value() (driver.Value, error) {
+	if !s.valid {
+		return nil, nil
+	}
+
+	return s.code, nil
+}
+
+type row struct {
+	Name      sql.NullString
+	Count     sql.NullInt64
+	UpdatedAt sql.NullTime
+	SKU       sku
+}
+
+func TestSQLTypes(t *testing.T) {
+	t.Run("creates null types", func(t *testing.T) {
+		result, err := NewDefault().CreateInstance(new(row), buildTable([][]string{
+			{"Name", "John"},
+			{"Count", "<nil>"},
+			{"UpdatedAt", "2020-11-05T16:01:54Z"},
+			{"SKU", "ab-1"},
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, &row{
+			Name:      sql.NullString{String: "John", Valid: true},
+			UpdatedAt: sql.NullTime{Time: time.Date(2020, 11, 5, 16, 1, 54, 0, time.UTC), Valid: true},
+			SKU:       sku{code: "AB-1", valid: true},
+		}, result)
+	})
+
+	t.Run("reports parse errors of the wrapped value", func(t *testing.T) {
+		_, err := NewDefault().CreateInstance(new(row), buildTable([][]string{{"Count", "many"}}))
+
+		assert.EqualError(t, err, `failed to parse table as *assistdog.row:
+- Count: strconv.ParseInt: parsing "many": invalid syntax`)
+	})
+
+	t.Run("compares null types", func(t *testing.T) {
+		actual := &row{
+			Name:  sql.NullString{String: "John", Valid: true},
+			Count: sql.NullInt64{Int64: 3, Valid: true},
+			SKU:   sku{code: "AB-1", valid: true},
+		}
+
+		err := NewDefault().CompareToInstance(actual, buildTable([][]string{
+			{"Name", "<nil>"},
+			{"Count", "4"},
+			{"UpdatedAt", "2020-11-05T16:01:54Z"},
+			{"SKU", "ab-1"},
+		}))
+
+		assert.EqualError(t, err, `comparison failed:
+- Count: expected 4, but got 3
+- Name: expected nil, but got John
+- UpdatedAt: expected 2020-11-05T16:01:54Z, but got nil`)
+	})
+
+	t.Run("compares scanners through their driver values", func(t *testing.T) {
+		err := NewDefault().CompareToInstance(&row{}, buildTable([][]string{{"SKU", "<nil>"}}))
+		assert.NoError(t, err)
+
+		err = NewDefault().CompareToInstance(&row{SKU: sku{code: "AB-1", valid: true}}, buildTable([][]string{{"SKU", "ab-2"}}))
+		assert.EqualError(t, err, `comparison failed:
+- SKU: expected AB-2, but got AB-1`)
+	})
+}